@@ -1,12 +1,12 @@
 module github.com/modernice/media-tools
 
-go 1.19
+go 1.21
 
 require (
 	github.com/disintegration/imaging v1.6.2
 	github.com/google/go-cmp v0.5.9
 	github.com/vitali-fedulov/images4 v1.2.1
-	golang.org/x/exp v0.0.0-20230817173708-d852ddb80c63
+	golang.org/x/image v0.11.0
 )
 
-require golang.org/x/image v0.11.0 // indirect
+require golang.org/x/text v0.12.0 // indirect
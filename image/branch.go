@@ -0,0 +1,98 @@
+package image
+
+import "fmt"
+
+var _ Processor = (*Brancher)(nil)
+
+// Brancher is a Processor that runs multiple [Pipeline]s on the same input
+// image and merges their results.
+type Brancher struct {
+	branches []NamedBranch
+}
+
+// NamedBranch is a [Pipeline] with a name. Images returned by a named branch
+// are tagged with "branch=<name>".
+type NamedBranch struct {
+	Name     string
+	Pipeline Pipeline
+}
+
+// Branch returns a [*Brancher] that runs each of the provided [Pipeline]s on
+// the input image and returns the merged results of all pipelines, in the
+// order the pipelines were provided.
+func Branch(pipelines ...Pipeline) *Brancher {
+	branches := make([]NamedBranch, len(pipelines))
+	for i, pipeline := range pipelines {
+		branches[i] = NamedBranch{Pipeline: pipeline}
+	}
+	return BranchNamed(branches...)
+}
+
+// BranchNamed returns a [*Brancher] that runs each of the provided branches on
+// the input image and returns the merged results of all branches, in the order
+// the branches were provided. The images of a branch are tagged with
+// "branch=<name>". Branches with an empty name are not tagged.
+func BranchNamed(branches ...NamedBranch) *Brancher {
+	return &Brancher{branches: branches}
+}
+
+// Process implements [Processor]. Each branch receives the input image as is,
+// including its tags. Because a [Pipeline] may only return a single original
+// image, only the first original image returned by the branches is kept as the
+// original. Original images of subsequent branches are returned as regular
+// images, without the [Original] tag.
+//
+// Branches share the [Lineage] of the [Pipeline] that runs the [*Brancher], so
+// that the images of each branch can be traced back to the input image.
+func (b *Brancher) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	var (
+		out         []Processed
+		hasOriginal bool
+		seen        = make(map[ImageID]bool)
+	)
+	for i, branch := range b.branches {
+		processed, err := runnerOf(ctx).nested(ctx).run(ctx, branch.Pipeline, input)
+		if err != nil {
+			if branch.Name != "" {
+				return nil, fmt.Errorf("branch %q: %w", branch.Name, err)
+			}
+			return nil, fmt.Errorf("branch #%d: %w", i, err)
+		}
+
+		for _, pimg := range processed {
+			if pimg.Original {
+				if hasOriginal {
+					pimg.Original = false
+					pimg.Tags = pimg.Tags.Without(Original)
+				}
+				hasOriginal = true
+			}
+
+			if branch.Name != "" {
+				pimg.Tags = pimg.Tags.With(BranchTag(branch.Name))
+			}
+
+			// If multiple branches return the same image, the copies are
+			// recorded as new images that are derived from the input image.
+			if seen[pimg.ID] {
+				pimg.ID = 0
+			}
+			seen[pimg.ID] = true
+
+			out = append(out, pimg)
+		}
+	}
+
+	return out, nil
+}
+
+// BranchKey is the key of the structured tag that is assigned to images of a
+// [NamedBranch].
+const BranchKey = "branch"
+
+// BranchTag returns the tag that is assigned to images of the named branch.
+func BranchTag(name string) string {
+	return FormatTag(Attr(BranchKey, name))
+}
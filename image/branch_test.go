@@ -0,0 +1,103 @@
+package image_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestBranch(t *testing.T) {
+	pipe := image.Pipeline{
+		image.BranchNamed(
+			image.NamedBranch{
+				Name: "avatar",
+				Pipeline: image.Pipeline{
+					image.Resize(image.DimensionMap{"sm": {64, 64}, "md": {128, 128}}),
+					image.Compress(compression.JPEG(80)),
+				},
+			},
+			image.NamedBranch{
+				Name: "banner",
+				Pipeline: image.Pipeline{
+					image.Resize(image.DimensionMap{"md": {640}, "lg": {960}}, image.DiscardInput(true)),
+					image.Compress(compression.JPEG(60)),
+				},
+			},
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 5 {
+		t.Fatalf("pipeline should return 5 images (including the original); got %d", len(result.Images))
+	}
+
+	if !result.Images[0].Original {
+		t.Fatalf("first image should be the original")
+	}
+
+	avatars := result.Find(image.BranchTag("avatar"))
+	if len(avatars) != 3 {
+		t.Fatalf("expected 3 avatar images (including the original); got %d", len(avatars))
+	}
+
+	for _, img := range avatars[1:] {
+		if img.Image.Bounds().Dx() != img.Image.Bounds().Dy() {
+			t.Fatalf("avatar image should be square; got %v", img.Image.Bounds())
+		}
+
+		if q := image.CompressionQuality(img.Tags); q != 80 {
+			t.Fatalf("avatar image should have compression quality %d; got %d", 80, q)
+		}
+	}
+
+	banners := result.Find(image.BranchTag("banner"))
+	if len(banners) != 2 {
+		t.Fatalf("expected 2 banner images; got %d", len(banners))
+	}
+
+	for _, img := range banners {
+		if img.Original {
+			t.Fatalf("banner images should not contain the original image")
+		}
+
+		if q := image.CompressionQuality(img.Tags); q != 60 {
+			t.Fatalf("banner image should have compression quality %d; got %d", 60, q)
+		}
+	}
+}
+
+func TestBranch_multipleOriginals(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Branch(
+			image.Pipeline{image.Tag(image.NewTags("foo"))},
+			image.Pipeline{image.Tag(image.NewTags("bar"))},
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 2 {
+		t.Fatalf("pipeline should return 2 images; got %d", len(result.Images))
+	}
+
+	if !result.Images[0].Original || !result.Images[0].Tags.Contains(image.Original) {
+		t.Fatalf("first image should be the original")
+	}
+
+	if result.Images[1].Original || result.Images[1].Tags.Contains(image.Original) {
+		t.Fatalf("second image should not be the original")
+	}
+
+	if !result.Images[1].Tags.Contains("bar") {
+		t.Fatalf("second image should have tag %q", "bar")
+	}
+}
@@ -0,0 +1,243 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+var _ DimensionResolver = (*Breakpoints)(nil)
+
+// Breakpoints is a [DimensionResolver] that computes responsive breakpoints
+// for an input image: widths that are chosen so that the encoded sizes of
+// successive widths differ by roughly a fixed number of bytes. Images that
+// compress well get few breakpoints, detailed images get more.
+//
+// The resolved dimensions preserve the aspect ratio of the input image and
+// are named by their width, e.g. "640w", so that a [Resizer] tags the resized
+// images with "size=640w".
+type Breakpoints struct {
+	compression SizedCompression
+	step        int
+	minWidth    int
+	maxWidth    int
+	maxCount    int
+	filter      imaging.ResampleFilter
+}
+
+// BreakpointsOption is an option for [Breakpoints].
+type BreakpointsOption func(*Breakpoints)
+
+// MinWidth returns a BreakpointsOption that sets the smallest breakpoint
+// width. Defaults to 320.
+func MinWidth(width int) BreakpointsOption {
+	return func(b *Breakpoints) {
+		b.minWidth = width
+	}
+}
+
+// MaxWidth returns a BreakpointsOption that sets the largest breakpoint width.
+// Breakpoints are never wider than the input image. Defaults to the width of
+// the input image.
+func MaxWidth(width int) BreakpointsOption {
+	return func(b *Breakpoints) {
+		b.maxWidth = width
+	}
+}
+
+// MaxBreakpoints returns a BreakpointsOption that limits the number of
+// breakpoints. Defaults to 10.
+func MaxBreakpoints(n int) BreakpointsOption {
+	return func(b *Breakpoints) {
+		b.maxCount = n
+	}
+}
+
+// BreakpointFilter returns a BreakpointsOption that sets the
+// [imaging.ResampleFilter] that is used to resize the input image when
+// measuring encoded sizes. Defaults to [imaging.Lanczos]. It should match the
+// filter of the [Resizer] that resizes the images.
+func BreakpointFilter(filter imaging.ResampleFilter) BreakpointsOption {
+	return func(b *Breakpoints) {
+		b.filter = filter
+	}
+}
+
+// ResponsiveBreakpoints returns [Breakpoints] that compute breakpoints whose
+// encoded sizes, as reported by the given compression, differ by roughly step
+// bytes.
+//
+//	pipe := image.Pipeline{
+//		image.Resize(image.ResponsiveBreakpoints(compression.JPEG(80), 20_000)),
+//		image.Compress(compression.JPEG(80)),
+//	}
+func ResponsiveBreakpoints(compression SizedCompression, step int, opts ...BreakpointsOption) *Breakpoints {
+	b := &Breakpoints{
+		compression: compression,
+		step:        step,
+		minWidth:    320,
+		maxCount:    10,
+		filter:      imaging.Lanczos,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Dimensions returns no dimensions because breakpoints depend on the input
+// image.
+func (b *Breakpoints) Dimensions() []Dimensions {
+	return nil
+}
+
+// ResolveDimensions implements [DimensionResolver]. It returns a
+// [DimensionMap] that maps the breakpoint names to their dimensions.
+func (b *Breakpoints) ResolveDimensions(img image.Image) (DimensionProvider, error) {
+	widths, err := b.Widths(img)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(DimensionMap, len(widths))
+	for _, width := range widths {
+		out[BreakpointName(width)] = Dimensions{width, 0}
+	}
+	return out, nil
+}
+
+// BreakpointName returns the name of the breakpoint with the given width, e.g.
+// "640w".
+func BreakpointName(width int) string {
+	return strconv.Itoa(width) + "w"
+}
+
+// Widths computes the breakpoint widths for the given image, in ascending
+// order.
+func (b *Breakpoints) Widths(img image.Image) ([]int, error) {
+	if b.step <= 0 {
+		return nil, errors.New("breakpoints: step must be positive")
+	}
+
+	maxWidth := img.Bounds().Dx()
+	if b.maxWidth > 0 && b.maxWidth < maxWidth {
+		maxWidth = b.maxWidth
+	}
+
+	minWidth := min(max(b.minWidth, 1), maxWidth)
+	if maxWidth <= 0 {
+		return nil, nil
+	}
+
+	if minWidth == maxWidth || b.maxCount == 1 {
+		return []int{maxWidth}, nil
+	}
+
+	m := &breakpointMeasurer{Breakpoints: b, img: img, sizes: make(map[int]int)}
+
+	maxSize, err := m.size(maxWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	minSize, err := m.size(minWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	widths := []int{maxWidth}
+	width, size := maxWidth, maxSize
+
+	for b.maxCount <= 0 || len(widths) < b.maxCount-1 {
+		target := size - b.step
+		if target <= minSize {
+			break
+		}
+
+		// Find the largest width whose encoded size does not exceed the target.
+		lo, hi := minWidth, width-1
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			s, err := m.size(mid)
+			if err != nil {
+				return nil, err
+			}
+			if s <= target {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+
+		if lo <= minWidth {
+			break
+		}
+
+		width = lo
+		if size, err = m.size(width); err != nil {
+			return nil, err
+		}
+		widths = append(widths, width)
+	}
+
+	widths = append(widths, minWidth)
+
+	for i, j := 0, len(widths)-1; i < j; i, j = i+1, j-1 {
+		widths[i], widths[j] = widths[j], widths[i]
+	}
+
+	return widths, nil
+}
+
+// breakpointMeasurer measures and caches the encoded sizes of an image at
+// different widths.
+//
+// Resizing a large image costs about the same for every target width, so
+// instead of resizing the input image for every measurement, the measurer
+// resizes from a pyramid of copies of the input that are halved in width,
+// using the smallest copy that is at least twice as wide as the measured
+// width. This keeps the cost of a measurement proportional to the measured
+// width instead of the size of the input.
+type breakpointMeasurer struct {
+	*Breakpoints
+	img     image.Image
+	sizes   map[int]int
+	pyramid []*image.NRGBA
+}
+
+func (m *breakpointMeasurer) size(width int) (int, error) {
+	if size, ok := m.sizes[width]; ok {
+		return size, nil
+	}
+
+	resized := imaging.Resize(m.source(width), width, 0, m.filter)
+	_, size, err := m.compression.CompressSized(resized)
+	if err != nil {
+		return 0, fmt.Errorf("measure encoded size at width %d: %w", width, err)
+	}
+
+	m.sizes[width] = size
+	return size, nil
+}
+
+// source returns the image to resize to the given width: the smallest image
+// of the pyramid that is at least twice as wide as width, or the input image.
+func (m *breakpointMeasurer) source(width int) image.Image {
+	src := m.img
+	for i := 0; ; i++ {
+		half := src.Bounds().Dx() / 2
+		if half < 2*width {
+			return src
+		}
+
+		if i == len(m.pyramid) {
+			m.pyramid = append(m.pyramid, imaging.Resize(src, half, 0, m.filter))
+		}
+		src = m.pyramid[i]
+	}
+}
@@ -0,0 +1,125 @@
+package image_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestBreakpoints_Widths(t *testing.T) {
+	img := imaging.Resize(newExample(), 480, 0, imaging.Linear)
+	jpeg := compression.JPEG(80)
+	step := 4000
+
+	widths, err := image.ResponsiveBreakpoints(jpeg, step, image.MinWidth(100)).Widths(img)
+	if err != nil {
+		t.Fatalf("compute breakpoints: %v", err)
+	}
+
+	if len(widths) < 3 {
+		t.Fatalf("expected at least 3 breakpoints; got %v", widths)
+	}
+
+	if widths[0] != 100 {
+		t.Fatalf("smallest breakpoint should be %d; got %d", 100, widths[0])
+	}
+
+	if last := widths[len(widths)-1]; last != 480 {
+		t.Fatalf("largest breakpoint should be %d; got %d", 480, last)
+	}
+
+	sizes := make([]int, len(widths))
+	for i, width := range widths {
+		if i > 0 && width <= widths[i-1] {
+			t.Fatalf("breakpoints should be ascending; got %v", widths)
+		}
+
+		_, size, err := jpeg.CompressSized(imaging.Resize(img, width, 0, imaging.Lanczos))
+		if err != nil {
+			t.Fatalf("compress image: %v", err)
+		}
+		sizes[i] = size
+	}
+
+	// All steps except the one to the smallest breakpoint are at least `step`
+	// bytes apart.
+	for i := 2; i < len(sizes); i++ {
+		if diff := sizes[i] - sizes[i-1]; diff < step {
+			t.Fatalf("breakpoints %d and %d should differ by at least %d bytes; got %d", widths[i-1], widths[i], step, diff)
+		}
+	}
+}
+
+func TestBreakpoints_Widths_bounds(t *testing.T) {
+	img := imaging.Resize(newExample(), 480, 0, imaging.Linear)
+	jpeg := compression.JPEG(80)
+
+	widths, err := image.ResponsiveBreakpoints(
+		jpeg, 1000,
+		image.MinWidth(200),
+		image.MaxWidth(400),
+		image.MaxBreakpoints(4),
+	).Widths(img)
+	if err != nil {
+		t.Fatalf("compute breakpoints: %v", err)
+	}
+
+	if len(widths) != 4 {
+		t.Fatalf("expected %d breakpoints; got %v", 4, widths)
+	}
+
+	if widths[0] != 200 || widths[3] != 400 {
+		t.Fatalf("breakpoints should range from %d to %d; got %v", 200, 400, widths)
+	}
+}
+
+func TestBreakpoints_Widths_smallInput(t *testing.T) {
+	img := imaging.Resize(newExample(), 100, 0, imaging.Linear)
+
+	widths, err := image.ResponsiveBreakpoints(compression.JPEG(80), 1000).Widths(img)
+	if err != nil {
+		t.Fatalf("compute breakpoints: %v", err)
+	}
+
+	if len(widths) != 1 || widths[0] != 100 {
+		t.Fatalf("input that is smaller than the minimum width should have a single breakpoint; got %v", widths)
+	}
+}
+
+func TestResizer_Process_Breakpoints(t *testing.T) {
+	img := imaging.Resize(newExample(), 480, 0, imaging.Linear)
+	jpeg := compression.JPEG(80)
+	breakpoints := image.ResponsiveBreakpoints(jpeg, 4000, image.MinWidth(100))
+
+	widths, err := breakpoints.Widths(img)
+	if err != nil {
+		t.Fatalf("compute breakpoints: %v", err)
+	}
+
+	pipe := image.Pipeline{image.Resize(breakpoints, image.DiscardInput(true))}
+
+	result, err := pipe.Run(context.Background(), img)
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != len(widths) {
+		t.Fatalf("expected %d images; got %d", len(widths), len(result.Images))
+	}
+
+	for _, width := range widths {
+		name := image.BreakpointName(width)
+		groups := result.GroupBySize()
+		group, ok := groups.Get(name)
+		if !ok {
+			t.Fatalf("result should contain an image tagged with %q; got %v", "size="+name, groups.Keys())
+		}
+
+		if w := group.Images[0].Image.Bounds().Dx(); w != width {
+			t.Fatalf("%q image should have width %d; got %d", name, width, w)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package image
 
 import (
+	"context"
+	"fmt"
 	"image"
 	"strconv"
-	"strings"
 
 	"github.com/modernice/media-tools/image/internal"
 )
@@ -16,6 +17,14 @@ const (
 
 	// AnonymousCompression is the name [Compression.Name] of a CompressionFunc.
 	AnonymousCompression = "anonymous"
+
+	// CompressionKey is the key of the structured tag that provides the name
+	// and quality of the [Compression] of a compressed image.
+	CompressionKey = "compression"
+
+	// QualityKey is the key of the quality attribute of the [CompressionKey]
+	// tag.
+	QualityKey = "quality"
 )
 
 // Compressor compresses images.
@@ -30,6 +39,18 @@ type Compression interface {
 	Compress(img image.Image) (image.Image, error)
 }
 
+// SizedCompression is a [Compression] that also reports the size of the
+// encoded image. If a [Compression] implements SizedCompression, the
+// [*Compressor] adds the size to the [Metadata] of compressed images, using
+// the [MetaBytes] key.
+type SizedCompression interface {
+	Compression
+
+	// CompressSized compresses an image and returns the compressed image
+	// together with the size of the encoded image in bytes.
+	CompressSized(img image.Image) (image.Image, int, error)
+}
+
 // CompressionFunc allow a function to be used as a [Compression].
 type CompressionFunc func(image.Image) (image.Image, error)
 
@@ -53,7 +74,8 @@ func CompressOriginal(v bool) CompressorOption {
 // Compress returns a [*Compressor] that compresses images using the provided
 // [Compression]. If the provided [Compression] has a `Tags() Tags` method,
 // the returned [*Compressor] will append these tags to compressed images
-// when calling [*Compressor.Process].
+// when calling [*Compressor.Process]. Likewise, if the [Compression] has a
+// `Metadata() Metadata` method, the [Metadata] is added to compressed images.
 func Compress(compression Compression, opts ...CompressorOption) *Compressor {
 	return CompressMany([]Compression{compression}, opts...)
 }
@@ -61,7 +83,8 @@ func Compress(compression Compression, opts ...CompressorOption) *Compressor {
 // CompressMany returns a [*Compressor] that compresses images using the provided
 // [Compression]s. If a provided [Compression] method has a `Tags() Tags` method,
 // the returned [*Compressor] will append these tags to compressed image when
-// calling [*Compressor.Process].
+// calling [*Compressor.Process]. Likewise, if a [Compression] has a
+// `Metadata() Metadata` method, the [Metadata] is added to compressed images.
 func CompressMany(compressions []Compression, opts ...CompressorOption) *Compressor {
 	c := &Compressor{compressions: compressions}
 	for _, opt := range opts {
@@ -70,15 +93,64 @@ func CompressMany(compressions []Compression, opts ...CompressorOption) *Compres
 	return c
 }
 
-// Compress compresses an image using the configured [Compression]s.
+// Compress compresses an image using the configured [Compression]s. If any
+// [Compression] fails, an error is returned.
 func (c *Compressor) Compress(img image.Image) ([]image.Image, error) {
-	out := make([]image.Image, len(c.compressions))
+	compressed, err := c.compress(context.Background(), img)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]image.Image, len(compressed))
+	for i, cimg := range compressed {
+		if cimg.err != nil {
+			return nil, cimg.err
+		}
+		out[i] = cimg.image
+	}
+
+	return out, nil
+}
+
+type compressedImage struct {
+	image image.Image
+
+	// size is the size of the encoded image, or -1 if unknown.
+	size int
+
+	// err is the error of the compression, if it failed.
+	err error
+}
+
+// compress compresses img using each configured Compression. The failure of a
+// single Compression is reported in its compressedImage; compress only fails
+// if ctx is canceled.
+func (c *Compressor) compress(ctx context.Context, img image.Image) ([]compressedImage, error) {
+	out := make([]compressedImage, len(c.compressions))
 	for i, compression := range c.compressions {
-		compressed, err := compression.Compress(img)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("compressed %d of %d compressions: %w", i, len(c.compressions), err)
+		}
+
+		var (
+			compressed image.Image
+			size       = -1
+			err        error
+		)
+		if sized, ok := compression.(SizedCompression); ok {
+			compressed, size, err = sized.CompressSized(img)
+		} else {
+			compressed, err = compression.Compress(img)
+		}
 		if err != nil {
-			return nil, err
+			out[i] = compressedImage{err: fmt.Errorf("compression %d: %w", i, err)}
+			continue
+		}
+
+		out[i] = compressedImage{
+			image: internal.ToNRGBA(compressed),
+			size:  size,
 		}
-		out[i] = internal.ToNRGBA(compressed)
 	}
 	return out, nil
 }
@@ -86,6 +158,10 @@ func (c *Compressor) Compress(img image.Image) ([]image.Image, error) {
 // Process implements [Processor]. By default, the original image will not be
 // compressed and returned as is to preserve quality. To also compress the
 // original image, pass the [CompressOriginal] option to [Compress].
+//
+// If some of the [Compression]s fail, Process returns the images of the
+// successful compressions together with a [*PartialError] that has an error
+// for each failed compression.
 func (c *Compressor) Process(ctx ProcessorContext) ([]Processed, error) {
 	pimg := ctx.Image()
 
@@ -93,23 +169,44 @@ func (c *Compressor) Process(ctx ProcessorContext) ([]Processed, error) {
 		return []Processed{pimg}, nil
 	}
 
-	compressed, err := c.Compress(ctx.Image().Image)
+	compressed, err := c.compress(ctx, ctx.Image().Image)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]Processed, len(c.compressions))
+	var (
+		out  = make([]Processed, 0, len(c.compressions))
+		errs []error
+	)
 	for i, compression := range c.compressions {
+		if compressed[i].err != nil {
+			errs = append(errs, compressed[i].err)
+			continue
+		}
+
 		var compressionTags Tags
 		if tagger, isTagger := compression.(interface{ Tags() Tags }); isTagger {
 			compressionTags = tagger.Tags()
 		}
 
-		out[i] = Processed{
-			Image:    compressed[i],
+		meta := pimg.Meta.withBounds(compressed[i].image)
+		if provider, ok := compression.(interface{ Metadata() Metadata }); ok {
+			meta = meta.Merge(provider.Metadata())
+		}
+		if compressed[i].size >= 0 {
+			meta[MetaBytes] = compressed[i].size
+		}
+
+		out = append(out, Processed{
+			Image:    compressed[i].image,
 			Tags:     pimg.Tags.With(Compressed).With(compressionTags...),
+			Meta:     meta,
 			Original: pimg.Original,
-		}
+		})
+	}
+
+	if len(errs) > 0 {
+		return out, &PartialError{Errs: errs}
 	}
 
 	return out, nil
@@ -122,18 +219,8 @@ func CompressionName(tags Tags) string {
 		return ""
 	}
 
-	for _, tag := range tags {
-		if !strings.HasPrefix(tag, "compression=") {
-			continue
-		}
-
-		parts := strings.Split(tag, ",")
-		compressionTag := parts[0]
-
-		return compressionTag[12:]
-	}
-
-	return ""
+	name, _ := tags.Get(CompressionKey)
+	return name
 }
 
 // CompressionQuality extracts the name of the compression quality from the tags
@@ -144,24 +231,22 @@ func CompressionQuality(tags Tags) int {
 		return -1
 	}
 
-	for _, tag := range tags {
-		if !strings.HasPrefix(tag, "compression=") {
-			continue
-		}
-
-		parts := strings.Split(tag, ",")
-		if len(parts) < 2 {
-			return -1
-		}
-
-		rawQuality := parts[1]
-		quality, err := strconv.Atoi(rawQuality[8:])
-		if err != nil {
-			return -1
-		}
+	rawQuality, ok := tags.Attributes(CompressionKey)[QualityKey]
+	if !ok {
+		return -1
+	}
 
-		return quality
+	quality, err := strconv.Atoi(rawQuality)
+	if err != nil {
+		return -1
 	}
 
-	return -1
+	return quality
+}
+
+// CompressionTag returns the structured tag for a [Compression] with the given
+// name and quality, e.g. "compression=jpeg,quality=75". Compressions should
+// return this tag from their `Tags() Tags` method.
+func CompressionTag(name string, quality int) string {
+	return FormatTag(Attr(CompressionKey, name), Attr(QualityKey, strconv.Itoa(quality)))
 }
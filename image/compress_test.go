@@ -3,10 +3,13 @@ package image_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	stdimage "image"
 	"image/jpeg"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/modernice/media-tools/image"
 	"github.com/modernice/media-tools/image/compression"
@@ -103,6 +106,19 @@ func TestCompressor_Process_CompressOriginal(t *testing.T) {
 	}
 }
 
+func TestCompressor_Process_deadline(t *testing.T) {
+	compressor := image.CompressMany([]image.Compression{compression.JPEG(80), compression.JPEG(50)})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	pctx := image.NewProcessorContext(ctx, image.Processed{Image: newExample()})
+
+	if _, err := compressor.Process(pctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Process() should fail with %q; got %q", context.DeadlineExceeded, err)
+	}
+}
+
 func getImageSize(t *testing.T, img stdimage.Image) int {
 	var buf bytes.Buffer
 	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
@@ -114,3 +130,50 @@ func getImageSize(t *testing.T, img stdimage.Image) int {
 func saveCompressed(t *testing.T, quality int, img stdimage.Image) {
 	saveOutImage(t, fmt.Sprintf("compressed-%d.jpg", quality), img)
 }
+
+func TestCompressor_Process_partialFailure(t *testing.T) {
+	failing := image.CompressionFunc(func(stdimage.Image) (stdimage.Image, error) {
+		return nil, errors.New("broken encoder")
+	})
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {40}}, image.DiscardInput(true)),
+		image.CompressMany([]image.Compression{
+			compression.JPEG(80),
+			failing,
+			compression.JPEG(50),
+		}),
+	}
+
+	for _, policy := range []image.ErrorPolicy{image.SkipFailed, image.CollectErrors} {
+		result, err := pipe.Run(context.Background(), newExample(), image.WithErrorPolicy(policy))
+		if policy == image.SkipFailed && err != nil {
+			t.Fatalf("[%s] run pipeline: %v", policy, err)
+		}
+		if policy == image.CollectErrors && err == nil {
+			t.Fatalf("[%s] Run() should return the collected errors", policy)
+		}
+
+		if len(result.Images) != 2 {
+			t.Fatalf("[%s] expected the 2 successfully compressed images; got %d", policy, len(result.Images))
+		}
+
+		for i, quality := range []int{80, 50} {
+			if q := image.CompressionQuality(result.Images[i].Tags); q != quality {
+				t.Fatalf("[%s] image %d should be compressed with quality %d; got %d", policy, i, quality, q)
+			}
+		}
+
+		if len(result.Errors) != 1 {
+			t.Fatalf("[%s] expected 1 error for the failed compression; got %d", policy, len(result.Errors))
+		}
+
+		if perr := result.Errors[0]; !strings.Contains(perr.Error(), "broken encoder") || !perr.Tags.Contains("size=sm") {
+			t.Fatalf("[%s] error should describe the failed compression of the %q image; got %q", policy, "size=sm", perr)
+		}
+	}
+
+	if _, err := pipe.Run(context.Background(), newExample()); err == nil {
+		t.Fatalf("Run() should fail with the default error policy")
+	}
+}
@@ -10,31 +10,49 @@ import (
 	"github.com/modernice/media-tools/image/internal"
 )
 
-// JPEG retrurns an [image.Compression] that compresses images using the JPEG
-// encoder's "quality" option.
-func JPEG(quality int) image.Compression {
+var _ image.SizedCompression = (*jpegCompression)(nil)
+
+// JPEG returns an [image.SizedCompression] that compresses images using the
+// JPEG encoder's "quality" option.
+func JPEG(quality int) image.SizedCompression {
 	return &jpegCompression{quality: quality}
 }
 
 type jpegCompression struct{ quality int }
 
 func (jc *jpegCompression) Compress(img stdimage.Image) (stdimage.Image, error) {
+	compressed, _, err := jc.CompressSized(img)
+	return compressed, err
+}
+
+// CompressSized implements [image.SizedCompression].
+func (jc *jpegCompression) CompressSized(img stdimage.Image) (stdimage.Image, int, error) {
 	var buf bytes.Buffer
 
 	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jc.quality}); err != nil {
-		return nil, fmt.Errorf("encode as JPEG: %w", err)
+		return nil, 0, fmt.Errorf("encode as JPEG: %w", err)
 	}
+	size := buf.Len()
 
 	decoded, err := jpeg.Decode(&buf)
 	if err != nil {
-		return nil, fmt.Errorf("decode JPEG: %w", err)
+		return nil, 0, fmt.Errorf("decode JPEG: %w", err)
 	}
 
-	return internal.ToNRGBA(decoded), nil
+	return internal.ToNRGBA(decoded), size, nil
 }
 
 // Tags returns the tags that should be assigned to images that are compressed
 // by the JPEG compression.
 func (jc *jpegCompression) Tags() image.Tags {
-	return image.NewTags(fmt.Sprintf("compression=jpeg,quality=%d", jc.quality))
+	return image.NewTags(image.CompressionTag("jpeg", jc.quality))
+}
+
+// Metadata returns the metadata that should be assigned to images that are
+// compressed by the JPEG compression.
+func (jc *jpegCompression) Metadata() image.Metadata {
+	return image.Metadata{
+		image.MetaCompression: "jpeg",
+		image.MetaQuality:     jc.quality,
+	}
 }
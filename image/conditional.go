@@ -0,0 +1,63 @@
+package image
+
+import "regexp"
+
+var _ Processor = (*Conditional)(nil)
+
+// Conditional is a Processor that applies another [Processor] only to images
+// that match a predicate. Images that do not match are passed through
+// unchanged.
+type Conditional struct {
+	match     func(Processed) bool
+	processor Processor
+}
+
+// When returns a [*Conditional] that applies the provided [Processor] only to
+// images for which the provided function returns true.
+func When(match func(Processed) bool, processor Processor) *Conditional {
+	return &Conditional{
+		match:     match,
+		processor: processor,
+	}
+}
+
+// WhenTagged returns a [*Conditional] that applies the provided [Processor]
+// only to images that have at least 1 of the given tags. If no tags are
+// provided, no image is matched.
+func WhenTagged(tags Tags, processor Processor) *Conditional {
+	return When(func(pimg Processed) bool {
+		for _, tag := range tags {
+			if pimg.Tags.Contains(tag) {
+				return true
+			}
+		}
+		return false
+	}, processor)
+}
+
+// WhenMatch returns a [*Conditional] that applies the provided [Processor]
+// only to images that have at least 1 tag that matches the given regular
+// expression.
+func WhenMatch(re *regexp.Regexp, processor Processor) *Conditional {
+	return When(func(pimg Processed) bool {
+		return len(pimg.Tags.Match(re)) > 0
+	}, processor)
+}
+
+// Unless returns a [*Conditional] that applies the provided [Processor] only
+// to images for which the provided function returns false.
+func Unless(match func(Processed) bool, processor Processor) *Conditional {
+	return When(func(pimg Processed) bool {
+		return !match(pimg)
+	}, processor)
+}
+
+// Process implements [Processor]. If the input image matches the configured
+// predicate, the wrapped [Processor] is called. Otherwise, the input image is
+// returned as is.
+func (c *Conditional) Process(ctx ProcessorContext) ([]Processed, error) {
+	if !c.match(ctx.Image()) {
+		return []Processed{ctx.Image()}, nil
+	}
+	return c.processor.Process(ctx)
+}
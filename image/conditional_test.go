@@ -0,0 +1,77 @@
+package image_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestWhenTagged(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "xl": {960}}),
+		image.WhenTagged(image.NewTags("size=xl"), image.Compress(compression.JPEG(60))),
+		image.Unless(func(p image.Processed) bool {
+			return p.Original || p.Tags.Contains("size=xl")
+		}, image.Compress(compression.JPEG(80))),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 3 {
+		t.Fatalf("pipeline should return 3 images (including the original); got %d", len(result.Images))
+	}
+
+	if result.Images[0].Tags.Contains(image.Compressed) {
+		t.Fatalf("original image should not be compressed")
+	}
+
+	sm := result.Find("size=sm")
+	if len(sm) != 1 {
+		t.Fatalf("expected 1 %q image; got %d", "size=sm", len(sm))
+	}
+
+	if q := image.CompressionQuality(sm[0].Tags); q != 80 {
+		t.Fatalf("%q image should be compressed with quality %d; got %d", "size=sm", 80, q)
+	}
+
+	xl := result.Find("size=xl")
+	if len(xl) != 1 {
+		t.Fatalf("expected 1 %q image; got %d", "size=xl", len(xl))
+	}
+
+	if q := image.CompressionQuality(xl[0].Tags); q != 60 {
+		t.Fatalf("%q image should be compressed with quality %d; got %d", "size=xl", 60, q)
+	}
+}
+
+func TestWhenMatch(t *testing.T) {
+	var calls int
+	processor := image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+		calls++
+		return []image.Processed{ctx.Image()}, nil
+	})
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}, "lg": {960}}),
+		image.WhenMatch(regexp.MustCompile(`^size=(sm|md)$`), processor),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 4 {
+		t.Fatalf("pipeline should return 4 images (including the original); got %d", len(result.Images))
+	}
+
+	if calls != 2 {
+		t.Fatalf("processor should be called %d times; was called %d times", 2, calls)
+	}
+}
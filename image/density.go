@@ -0,0 +1,154 @@
+package image
+
+import (
+	"image"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DensityKey is the key of the structured tag that provides the pixel density
+// of a resized image, e.g. "density=2x".
+const DensityKey = "density"
+
+// Densities returns a ResizerOption that expands each configured [Dimensions]
+// into pixel-density variants for high-resolution displays. For example, with
+// Densities(1, 2, 3), the dimensions 320x0 are resized to 320x0, 640x0 and
+// 960x0, and the resized images are tagged with "density=1x", "density=2x"
+// and "density=3x", in addition to their "size=" tags.
+//
+// Variants with a density greater than 1 are skipped if they would upscale
+// the input image. Use [DensitySrcset] to build a srcset attribute from the
+// resized images.
+func Densities(densities ...float64) ResizerOption {
+	return func(r *Resizer) {
+		r.densities = nil
+		for _, d := range densities {
+			if d > 0 && !math.IsInf(d, 0) {
+				r.densities = append(r.densities, d)
+			}
+		}
+		slices.Sort(r.densities)
+		r.densities = slices.Compact(r.densities)
+	}
+}
+
+// FormatDensity formats a pixel density as a srcset descriptor, e.g. "2x".
+func FormatDensity(density float64) string {
+	return strconv.FormatFloat(density, 'f', -1, 64) + "x"
+}
+
+// Density returns the pixel density of a processed image from its tags. If
+// the image has no "density=" tag, Density returns false.
+func Density(tags Tags) (float64, bool) {
+	v, ok := tags.Get(DensityKey)
+	if !ok {
+		return 0, false
+	}
+
+	density, err := strconv.ParseFloat(strings.TrimSuffix(v, "x"), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return density, true
+}
+
+// DensitySrcset returns a srcset attribute value with "x" descriptors for the
+// given images, e.g. "img-sm.jpg 1x, img-sm@2x.jpg 2x". url returns the URL of
+// an image. Images without a "density=" tag are ignored. The candidates are
+// sorted by density; if multiple images have the same density, the first one
+// is used. Typically, the images are the images of a single size and
+// compression, e.g. the result of
+//
+//	result.Query("size=sm AND compression=jpeg")
+func DensitySrcset(images []Processed, url func(Processed) string) string {
+	type candidate struct {
+		density float64
+		url     string
+	}
+
+	var candidates []candidate
+	for _, img := range images {
+		density, ok := Density(img.Tags)
+		if !ok || slices.ContainsFunc(candidates, func(c candidate) bool { return c.density == density }) {
+			continue
+		}
+		candidates = append(candidates, candidate{density, url(img)})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.density < b.density:
+			return -1
+		case a.density > b.density:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	parts := make([]string, len(candidates))
+	for i, c := range candidates {
+		parts[i] = c.url + " " + FormatDensity(c.density)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// DensitySrcset returns a srcset attribute value with "x" descriptors for the
+// images of the group. See [DensitySrcset].
+func (g Group) DensitySrcset(url func(Processed) string) string {
+	return DensitySrcset(g.Images, url)
+}
+
+// resizeVariant is a single image that a Resizer produces for an input image.
+type resizeVariant struct {
+	// dimensions are the configured dimensions.
+	dimensions Dimensions
+
+	// names are the names of the configured dimensions.
+	names []string
+
+	// density is the pixel density, or 0 if the Resizer has no densities.
+	density float64
+
+	// target are the dimensions the input image is resized to.
+	target Dimensions
+}
+
+// variants returns the variants for the given input image and dimensions.
+func (r *Resizer) variants(provider DimensionProvider, bounds image.Rectangle, dims []Dimensions) []resizeVariant {
+	if len(r.densities) == 0 {
+		out := make([]resizeVariant, len(dims))
+		for i, dim := range dims {
+			out[i] = resizeVariant{dimensions: dim, names: dimensionNames(provider, dim), target: dim}
+		}
+		return out
+	}
+
+	var out []resizeVariant
+	for _, dim := range dims {
+		names := dimensionNames(provider, dim)
+		for _, density := range r.densities {
+			target := Dimensions{scaleDensity(dim.Width(), density), scaleDensity(dim.Height(), density)}
+			if density > 1 && upscales(bounds, target) {
+				continue
+			}
+			out = append(out, resizeVariant{dimensions: dim, names: names, density: density, target: target})
+		}
+	}
+	return out
+}
+
+func scaleDensity(v int, density float64) int {
+	return int(math.Round(float64(v) * density))
+}
+
+// upscales returns whether resizing an image with the given bounds to dim
+// would make the image larger in either direction.
+func upscales(bounds image.Rectangle, dim Dimensions) bool {
+	size := resolveSize(bounds, dim)
+	return size.Width() > bounds.Dx() || size.Height() > bounds.Dy()
+}
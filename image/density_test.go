@@ -0,0 +1,83 @@
+package image_test
+
+import (
+	"context"
+	"fmt"
+	stdimage "image"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+func TestDensities(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{
+			"sm": {40, 0},
+			"lg": {80, 0},
+		}, image.Densities(1, 2, 3), image.DiscardInput(true)),
+	}
+
+	result, err := pipe.Run(context.Background(), stdimage.NewNRGBA(stdimage.Rect(0, 0, 200, 100)))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	want := map[string]struct {
+		width   int
+		density float64
+	}{
+		"size=sm AND density=1x": {40, 1},
+		"size=sm AND density=2x": {80, 2},
+		"size=sm AND density=3x": {120, 3},
+		"size=lg AND density=1x": {80, 1},
+		"size=lg AND density=2x": {160, 2},
+	}
+
+	if len(result.Images) != len(want) {
+		t.Fatalf("expected %d images; got %d", len(want), len(result.Images))
+	}
+
+	for expr, want := range want {
+		img, err := result.Single(expr)
+		if err != nil {
+			t.Fatalf("find %q image: %v", expr, err)
+		}
+
+		if w := img.Image.Bounds().Dx(); w != want.width {
+			t.Fatalf("%q image should have width %d; got %d", expr, want.width, w)
+		}
+
+		if density, ok := img.Meta.Float(image.MetaDensity); !ok || density != want.density {
+			t.Fatalf("%q image should have %q metadata %v; got %v", expr, image.MetaDensity, want.density, img.Meta[image.MetaDensity])
+		}
+	}
+
+	if _, err := result.Single("size=lg AND density=3x"); err == nil {
+		t.Fatalf("%q variant should be skipped because it would upscale the input image", "size=lg AND density=3x")
+	}
+}
+
+func TestDensitySrcset(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {40, 0}}, image.Densities(2, 1), image.DiscardInput(true)),
+	}
+
+	result, err := pipe.Run(context.Background(), stdimage.NewNRGBA(stdimage.Rect(0, 0, 200, 100)))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	group, ok := result.GroupBySize().Get("sm")
+	if !ok {
+		t.Fatalf("result should have a %q group", "sm")
+	}
+
+	srcset := group.DensitySrcset(func(img image.Processed) string {
+		density, _ := image.Density(img.Tags)
+		return fmt.Sprintf("sm@%s.jpg", image.FormatDensity(density))
+	})
+
+	if want := "sm@1x.jpg 1x, sm@2x.jpg 2x"; srcset != want {
+		t.Fatalf("DensitySrcset() should return %q; got %q", want, srcset)
+	}
+}
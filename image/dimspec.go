@@ -0,0 +1,321 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ DimensionResolver = DimensionSpecs(nil)
+	_ DimensionResolver = DimensionSpecMap(nil)
+)
+
+// ErrInvalidDimensionSpec is returned when parsing an invalid [DimensionSpec].
+var ErrInvalidDimensionSpec = errors.New("invalid dimension spec")
+
+// DimensionResolver is a [DimensionProvider] whose dimensions depend on the
+// input image. The [Resizer] calls ResolveDimensions for every image that it
+// processes and resizes the image to the resolved dimensions. Dimensions
+// should return the dimensions that are known without an input image.
+type DimensionResolver interface {
+	DimensionProvider
+
+	// ResolveDimensions returns the dimensions for the given input image. If
+	// the returned DimensionProvider has a `Tag(Dimensions) string` or
+	// `Names(Dimensions) []string` method, the resized images are tagged
+	// accordingly.
+	ResolveDimensions(img image.Image) (DimensionProvider, error)
+}
+
+// DimensionSpec specifies the dimensions of a resized image, either as
+// absolute [Dimensions], or relative to the input image. Exactly one of
+// Dimensions, Scale and MaxPixels should be set.
+//
+// DimensionSpecs can be parsed from strings using [ParseDimensionSpec].
+type DimensionSpec struct {
+	// Dimensions are absolute dimensions. A zero width or height preserves the
+	// aspect ratio of the input image.
+	Dimensions Dimensions
+
+	// Scale scales the input image by the given factor, e.g. 0.5 for half
+	// the size of the input image.
+	Scale float64
+
+	// MaxPixels downscales the input image, preserving its aspect ratio, so
+	// that it has at most the given number of pixels. Images that are already
+	// small enough are not resized.
+	MaxPixels int
+}
+
+// ParseDimensionSpec parses a [DimensionSpec]. The following formats are
+// supported:
+//
+//	640x480   absolute width and height
+//	640w      absolute width, preserving the aspect ratio (also "640")
+//	x480      absolute height, preserving the aspect ratio (also "480h")
+//	50%       relative to the input image
+//	2x        relative to the input image, as a factor (also "0.5x")
+//	max 2MP   at most 2 megapixels (also "max 2000000px")
+func ParseDimensionSpec(s string) (DimensionSpec, error) {
+	spec, err := parseDimensionSpec(strings.ToLower(strings.TrimSpace(s)))
+	if err != nil {
+		return DimensionSpec{}, fmt.Errorf("parse %q: %w", s, err)
+	}
+	return spec, nil
+}
+
+// MustParseDimensionSpec parses a [DimensionSpec] and panics if s is invalid.
+func MustParseDimensionSpec(s string) DimensionSpec {
+	spec, err := ParseDimensionSpec(s)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+func parseDimensionSpec(s string) (DimensionSpec, error) {
+	switch {
+	case s == "":
+		return DimensionSpec{}, ErrInvalidDimensionSpec
+
+	case strings.HasPrefix(s, "max "):
+		v := strings.TrimSpace(s[4:])
+		var factor float64
+		switch {
+		case strings.HasSuffix(v, "mp"):
+			v, factor = v[:len(v)-2], 1e6
+		case strings.HasSuffix(v, "px"):
+			v, factor = v[:len(v)-2], 1
+		default:
+			return DimensionSpec{}, fmt.Errorf("%w: unknown unit", ErrInvalidDimensionSpec)
+		}
+		n, err := parsePositiveFloat(v)
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		if n*factor < 1 {
+			return DimensionSpec{}, fmt.Errorf("%w: pixel limit must be at least 1 pixel", ErrInvalidDimensionSpec)
+		}
+		return DimensionSpec{MaxPixels: int(n * factor)}, nil
+
+	case strings.HasSuffix(s, "%"):
+		n, err := parsePositiveFloat(s[:len(s)-1])
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Scale: n / 100}, nil
+
+	case strings.HasSuffix(s, "x"):
+		n, err := parsePositiveFloat(s[:len(s)-1])
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Scale: n}, nil
+
+	case strings.HasSuffix(s, "w"):
+		n, err := parsePositiveInt(s[:len(s)-1])
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Dimensions: Dimensions{n, 0}}, nil
+
+	case strings.HasSuffix(s, "h"):
+		n, err := parsePositiveInt(s[:len(s)-1])
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Dimensions: Dimensions{0, n}}, nil
+
+	case strings.HasPrefix(s, "x"):
+		n, err := parsePositiveInt(s[1:])
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Dimensions: Dimensions{0, n}}, nil
+
+	case strings.Contains(s, "x"):
+		rawWidth, rawHeight, _ := strings.Cut(s, "x")
+		width, err := parsePositiveInt(rawWidth)
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		height, err := parsePositiveInt(rawHeight)
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Dimensions: Dimensions{width, height}}, nil
+
+	default:
+		n, err := parsePositiveInt(s)
+		if err != nil {
+			return DimensionSpec{}, err
+		}
+		return DimensionSpec{Dimensions: Dimensions{n, 0}}, nil
+	}
+}
+
+func parsePositiveInt(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %q is not a positive integer", ErrInvalidDimensionSpec, s)
+	}
+	return n, nil
+}
+
+func parsePositiveFloat(s string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("%w: %q is not a positive number", ErrInvalidDimensionSpec, s)
+	}
+	return n, nil
+}
+
+// IsRelative returns whether the spec depends on the input image.
+func (spec DimensionSpec) IsRelative() bool {
+	return spec.Scale > 0 || spec.MaxPixels > 0
+}
+
+// Resolve returns the [Dimensions] for an input image with the given bounds.
+func (spec DimensionSpec) Resolve(bounds image.Rectangle) Dimensions {
+	width, height := bounds.Dx(), bounds.Dy()
+
+	switch {
+	case spec.Scale > 0:
+		return Dimensions{scaleDimension(width, spec.Scale), scaleDimension(height, spec.Scale)}
+	case spec.MaxPixels > 0:
+		if width*height <= spec.MaxPixels {
+			return Dimensions{width, height}
+		}
+		factor := math.Sqrt(float64(spec.MaxPixels) / float64(width*height))
+		return Dimensions{
+			max(1, int(math.Floor(float64(width)*factor))),
+			max(1, int(math.Floor(float64(height)*factor))),
+		}
+	default:
+		return spec.Dimensions
+	}
+}
+
+func scaleDimension(v int, scale float64) int {
+	return max(1, int(math.Round(float64(v)*scale)))
+}
+
+// String returns the spec in the format that is parsed by [ParseDimensionSpec].
+func (spec DimensionSpec) String() string {
+	switch {
+	case spec.Scale > 0:
+		return strconv.FormatFloat(spec.Scale, 'f', -1, 64) + "x"
+	case spec.MaxPixels > 0:
+		return fmt.Sprintf("max %dpx", spec.MaxPixels)
+	case spec.Dimensions.Height() == 0:
+		return fmt.Sprintf("%dw", spec.Dimensions.Width())
+	case spec.Dimensions.Width() == 0:
+		return fmt.Sprintf("x%d", spec.Dimensions.Height())
+	default:
+		return fmt.Sprintf("%dx%d", spec.Dimensions.Width(), spec.Dimensions.Height())
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (spec DimensionSpec) MarshalText() ([]byte, error) {
+	return []byte(spec.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (spec *DimensionSpec) UnmarshalText(text []byte) error {
+	parsed, err := ParseDimensionSpec(string(text))
+	if err != nil {
+		return err
+	}
+	*spec = parsed
+	return nil
+}
+
+// DimensionSpecs is a list of [DimensionSpec]s. DimensionSpecs implements
+// [DimensionResolver], so it can be passed to [Resize].
+type DimensionSpecs []DimensionSpec
+
+// ParseDimensionSpecs parses a comma-separated list of [DimensionSpec]s, e.g.
+// "640w, 50%, max 2MP".
+func ParseDimensionSpecs(s string) (DimensionSpecs, error) {
+	var out DimensionSpecs
+	for _, raw := range strings.Split(s, ",") {
+		spec, err := ParseDimensionSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
+
+// Dimensions returns the absolute dimensions of the list.
+func (specs DimensionSpecs) Dimensions() []Dimensions {
+	var out []Dimensions
+	for _, spec := range specs {
+		if !spec.IsRelative() {
+			out = append(out, spec.Dimensions)
+		}
+	}
+	return out
+}
+
+// ResolveDimensions implements [DimensionResolver].
+func (specs DimensionSpecs) ResolveDimensions(img image.Image) (DimensionProvider, error) {
+	out := make(DimensionList, len(specs))
+	for i, spec := range specs {
+		out[i] = spec.Resolve(img.Bounds())
+	}
+	return out, nil
+}
+
+// DimensionSpecMap provides named [DimensionSpec]s. DimensionSpecMap
+// implements [DimensionResolver], so it can be passed to [Resize]. Like with
+// a [DimensionMap], resized images are tagged with the names of their specs.
+type DimensionSpecMap map[string]DimensionSpec
+
+// ParseDimensionSpecMap parses a comma-separated list of named
+// [DimensionSpec]s, e.g. "sm=640w, md=960x540, half=50%, preview=max 2MP".
+func ParseDimensionSpecMap(s string) (DimensionSpecMap, error) {
+	out := make(DimensionSpecMap)
+	for _, raw := range strings.Split(s, ",") {
+		name, rawSpec, ok := strings.Cut(raw, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("parse %q: %w: expected <name>=<spec>", raw, ErrInvalidDimensionSpec)
+		}
+
+		spec, err := ParseDimensionSpec(rawSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		out[name] = spec
+	}
+	return out, nil
+}
+
+// Dimensions returns the absolute dimensions of the map.
+func (specs DimensionSpecMap) Dimensions() []Dimensions {
+	var out []Dimensions
+	for _, spec := range specs {
+		if !spec.IsRelative() {
+			out = append(out, spec.Dimensions)
+		}
+	}
+	return sortDimensions(out)
+}
+
+// ResolveDimensions implements [DimensionResolver]. It returns a
+// [DimensionMap].
+func (specs DimensionSpecMap) ResolveDimensions(img image.Image) (DimensionProvider, error) {
+	out := make(DimensionMap, len(specs))
+	for name, spec := range specs {
+		out[name] = spec.Resolve(img.Bounds())
+	}
+	return out, nil
+}
@@ -0,0 +1,110 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+func TestParseDimensionSpec(t *testing.T) {
+	bounds := stdimage.Rect(0, 0, 4000, 3000)
+
+	tests := []struct {
+		spec     string
+		want     image.DimensionSpec
+		resolved image.Dimensions
+	}{
+		{"640x480", image.DimensionSpec{Dimensions: image.Dimensions{640, 480}}, image.Dimensions{640, 480}},
+		{"640w", image.DimensionSpec{Dimensions: image.Dimensions{640}}, image.Dimensions{640}},
+		{"640", image.DimensionSpec{Dimensions: image.Dimensions{640}}, image.Dimensions{640}},
+		{"x480", image.DimensionSpec{Dimensions: image.Dimensions{0, 480}}, image.Dimensions{0, 480}},
+		{"480h", image.DimensionSpec{Dimensions: image.Dimensions{0, 480}}, image.Dimensions{0, 480}},
+		{"50%", image.DimensionSpec{Scale: 0.5}, image.Dimensions{2000, 1500}},
+		{"2x", image.DimensionSpec{Scale: 2}, image.Dimensions{8000, 6000}},
+		{"0.25x", image.DimensionSpec{Scale: 0.25}, image.Dimensions{1000, 750}},
+		{"max 3MP", image.DimensionSpec{MaxPixels: 3_000_000}, image.Dimensions{2000, 1500}},
+		{"MAX 20MP", image.DimensionSpec{MaxPixels: 20_000_000}, image.Dimensions{4000, 3000}},
+		{"max 480000px", image.DimensionSpec{MaxPixels: 480_000}, image.Dimensions{800, 600}},
+		{" 640X480 ", image.DimensionSpec{Dimensions: image.Dimensions{640, 480}}, image.Dimensions{640, 480}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := image.ParseDimensionSpec(tt.spec)
+			if err != nil {
+				t.Fatalf("parse spec: %v", err)
+			}
+
+			if spec != tt.want {
+				t.Fatalf("ParseDimensionSpec() should return %+v; got %+v", tt.want, spec)
+			}
+
+			if resolved := spec.Resolve(bounds); resolved != tt.resolved {
+				t.Fatalf("Resolve() should return %v; got %v", tt.resolved, resolved)
+			}
+
+			reparsed, err := image.ParseDimensionSpec(spec.String())
+			if err != nil || reparsed != spec {
+				t.Fatalf("String() should return a parseable spec; %q parsed to %+v (%v)", spec.String(), reparsed, err)
+			}
+		})
+	}
+}
+
+func TestParseDimensionSpec_invalid(t *testing.T) {
+	for _, spec := range []string{"", "abc", "0x480", "-5%", "max 2", "max xMP", "640x-1", "x", "max 0.5px", "max 0.0000001MP"} {
+		if _, err := image.ParseDimensionSpec(spec); !errors.Is(err, image.ErrInvalidDimensionSpec) {
+			t.Fatalf("ParseDimensionSpec(%q) should fail with %q; got %v", spec, image.ErrInvalidDimensionSpec, err)
+		}
+	}
+}
+
+func TestResizer_Process_DimensionSpecMap(t *testing.T) {
+	specs, err := image.ParseDimensionSpecMap("sm=40w, half=50%, preview=max 1200px")
+	if err != nil {
+		t.Fatalf("parse specs: %v", err)
+	}
+
+	pipe := image.Pipeline{image.Resize(specs, image.DiscardInput(true))}
+
+	for _, bounds := range []stdimage.Rectangle{stdimage.Rect(0, 0, 100, 60), stdimage.Rect(0, 0, 200, 120)} {
+		result, err := pipe.Run(context.Background(), stdimage.NewNRGBA(bounds))
+		if err != nil {
+			t.Fatalf("run pipeline: %v", err)
+		}
+
+		if len(result.Images) != 3 {
+			t.Fatalf("expected 3 images; got %d", len(result.Images))
+		}
+
+		half, err := result.Single("size=half")
+		if err != nil {
+			t.Fatalf("find %q image: %v", "size=half", err)
+		}
+
+		if got, want := half.Image.Bounds().Size(), bounds.Size().Div(2); got != want {
+			t.Fatalf("%q image should have size %v; got %v", "size=half", want, got)
+		}
+
+		preview, err := result.Single("size=preview")
+		if err != nil {
+			t.Fatalf("find %q image: %v", "size=preview", err)
+		}
+
+		if size := preview.Image.Bounds().Size(); size.X*size.Y > 1200 {
+			t.Fatalf("%q image should have at most %d pixels; got %v", "size=preview", 1200, size)
+		}
+
+		sm, err := result.Single("size=sm")
+		if err != nil {
+			t.Fatalf("find %q image: %v", "size=sm", err)
+		}
+
+		if w := sm.Image.Bounds().Dx(); w != 40 {
+			t.Fatalf("%q image should have width %d; got %d", "size=sm", 40, w)
+		}
+	}
+}
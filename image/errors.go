@@ -0,0 +1,152 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMultipleOriginals is returned by [Pipeline.Run] if a [Processor] returns
+// more than one image that is flagged as [Processed.Original].
+var ErrMultipleOriginals = errors.New(`more than one "original" image`)
+
+// ErrorPolicy determines how a [Pipeline] handles errors of its Processors.
+type ErrorPolicy int
+
+const (
+	// FailFast stops the [Pipeline] at the first error and discards all
+	// images. This is the default.
+	FailFast ErrorPolicy = iota
+
+	// SkipFailed skips images that a [Processor] fails to process and
+	// continues with the remaining images. [Pipeline.Run] returns the
+	// successfully processed images and does not return an error. The
+	// skipped errors are reported in [PipelineResult.Errors].
+	SkipFailed
+
+	// CollectErrors behaves like SkipFailed, but [Pipeline.Run] additionally
+	// returns an error that joins all errors in [PipelineResult.Errors].
+	CollectErrors
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case FailFast:
+		return "fail_fast"
+	case SkipFailed:
+		return "skip_failed"
+	case CollectErrors:
+		return "collect_errors"
+	default:
+		return "unknown"
+	}
+}
+
+// WithErrorPolicy returns a RunOption that sets the [ErrorPolicy] of a
+// [Pipeline] run. Pipelines that are run by a [Processor], like the branches
+// of a [*Brancher], inherit the [ErrorPolicy].
+func WithErrorPolicy(policy ErrorPolicy) RunOption {
+	return func(r *runner) {
+		r.errorPolicy = policy
+	}
+}
+
+// ProcessorError is an error that occurred while a [Processor] of a
+// [Pipeline] processed an image. All errors that are returned by
+// [Pipeline.Run] for a failed [Processor] are ProcessorErrors; use
+// [errors.As] to access them.
+type ProcessorError struct {
+	// Stage is the index of the [Processor] within its [Pipeline].
+	Stage int
+
+	// Processor is the [Processor] that failed.
+	Processor Processor
+
+	// Name is the name of the [Processor], as returned by [ProcessorName].
+	Name string
+
+	// Input is the image that the [Processor] failed to process.
+	Input Processed
+
+	// Tags are the tags of the input image.
+	Tags Tags
+
+	// Err is the error that was returned by the [Processor].
+	Err error
+}
+
+func newProcessorError(stage Stage, input Processed, err error) *ProcessorError {
+	return &ProcessorError{
+		Stage:     stage.Index,
+		Processor: stage.Processor,
+		Name:      ProcessorName(stage.Processor),
+		Input:     input,
+		Tags:      input.Tags,
+		Err:       err,
+	}
+}
+
+func (err *ProcessorError) Error() string {
+	return fmt.Sprintf("stage %d: %s processor [%s]: %v", err.Stage, err.Name, strings.Join(err.Tags, " "), err.Err)
+}
+
+// Unwrap returns the underlying error.
+func (err *ProcessorError) Unwrap() error {
+	return err.Err
+}
+
+// PartialError is returned by a [Processor] that produced some of its output
+// images but failed to produce others, e.g. a [*Compressor] with one failing
+// [Compression]. The Processor returns the successfully produced images
+// together with the PartialError. If the [ErrorPolicy] of the [Pipeline] is
+// [SkipFailed] or [CollectErrors], the produced images are kept and each
+// error in Errs is reported as a separate [*ProcessorError]. With [FailFast],
+// the [Pipeline] fails like for any other error.
+type PartialError struct {
+	// Errs are the errors of the outputs that failed.
+	Errs []error
+}
+
+func (err *PartialError) Error() string {
+	msgs := make([]string, len(err.Errs))
+	for i, e := range err.Errs {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d output(s) failed: %s", len(err.Errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the underlying errors.
+func (err *PartialError) Unwrap() []error {
+	return err.Errs
+}
+
+// processorErrors returns the [*ProcessorError]s of an error that was
+// returned by runner.process.
+func processorErrors(err error) []*ProcessorError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []*ProcessorError
+		for _, e := range joined.Unwrap() {
+			out = append(out, processorErrors(e)...)
+		}
+		return out
+	}
+
+	var perr *ProcessorError
+	if errors.As(err, &perr) {
+		return []*ProcessorError{perr}
+	}
+
+	return nil
+}
+
+// ProcessorName returns the name of a [Processor]. If the [Processor] has a
+// `Name() string` method that returns a non-empty name, that name is returned.
+// Otherwise, the name is the type of the [Processor], e.g. "*image.Resizer".
+func ProcessorName(p Processor) string {
+	if named, ok := p.(interface{ Name() string }); ok {
+		if name := named.Name(); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", p)
+}
@@ -0,0 +1,183 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+func TestWithErrorPolicy(t *testing.T) {
+	mockError := errors.New("mock error")
+	failing := image.WhenTagged(image.NewTags("size=md"), image.ProcessorFunc(func(image.ProcessorContext) ([]image.Processed, error) {
+		return nil, mockError
+	}))
+
+	newPipeline := func() image.Pipeline {
+		return image.Pipeline{
+			image.Resize(image.DimensionMap{"sm": {360}, "md": {640}, "lg": {960}}),
+			failing,
+			image.Tag(image.NewTags("foo")),
+		}
+	}
+
+	t.Run("FailFast", func(t *testing.T) {
+		result, err := newPipeline().Run(context.Background(), newExample(), image.WithErrorPolicy(image.FailFast))
+		if !errors.Is(err, mockError) {
+			t.Fatalf("Run() should fail with %q; got %q", mockError, err)
+		}
+
+		if len(result.Images) != 0 {
+			t.Fatalf("result should not contain images; got %d", len(result.Images))
+		}
+	})
+
+	for _, policy := range []image.ErrorPolicy{image.SkipFailed, image.CollectErrors} {
+		t.Run(policy.String(), func(t *testing.T) {
+			result, err := newPipeline().Run(context.Background(), newExample(), image.WithErrorPolicy(policy))
+
+			if policy == image.SkipFailed && err != nil {
+				t.Fatalf("Run() should not fail; got %q", err)
+			}
+
+			if policy == image.CollectErrors && !errors.Is(err, mockError) {
+				t.Fatalf("Run() should fail with %q; got %q", mockError, err)
+			}
+
+			if len(result.Images) != 3 {
+				t.Fatalf("result should contain 3 images (including the original); got %d", len(result.Images))
+			}
+
+			for _, img := range result.Images {
+				if img.Tags.Contains("size=md") {
+					t.Fatalf("result should not contain the failed image")
+				}
+
+				if !img.Tags.Contains("foo") {
+					t.Fatalf("remaining images should be processed by subsequent processors")
+				}
+			}
+
+			if len(result.Errors) != 1 {
+				t.Fatalf("result should contain 1 error; got %d", len(result.Errors))
+			}
+
+			perr := result.Errors[0]
+
+			if perr.Stage != 1 {
+				t.Fatalf("error should be reported for stage %d; got %d", 1, perr.Stage)
+			}
+
+			if perr.Processor != failing {
+				t.Fatalf("error should be reported for the failing processor; got %T", perr.Processor)
+			}
+
+			if !perr.Input.Tags.Contains("size=md") {
+				t.Fatalf("error should be reported for the %q image; got %v", "size=md", perr.Input.Tags)
+			}
+
+			if !errors.Is(perr, mockError) {
+				t.Fatalf("error should wrap %q; got %q", mockError, perr)
+			}
+		})
+	}
+}
+
+func TestWithErrorPolicy_Branch(t *testing.T) {
+	mockError := errors.New("mock error")
+
+	pipe := image.Pipeline{
+		image.Branch(
+			image.Pipeline{image.Resize(image.DimensionList{{360}})},
+			image.Pipeline{image.ProcessorFunc(func(image.ProcessorContext) ([]image.Processed, error) {
+				return nil, mockError
+			})},
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample(), image.WithErrorPolicy(image.SkipFailed))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 2 {
+		t.Fatalf("result should contain the 2 images of the successful branch; got %d", len(result.Images))
+	}
+
+	if len(result.Errors) != 1 || !errors.Is(result.Errors[0], mockError) {
+		t.Fatalf("result should contain the error of the failed branch; got %v", result.Errors)
+	}
+}
+
+func TestProcessorError(t *testing.T) {
+	mockError := errors.New("mock error")
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}}, image.DiscardInput(true)),
+		image.ProcessorFunc(func(image.ProcessorContext) ([]image.Processed, error) {
+			return nil, mockError
+		}),
+	}
+
+	_, err := pipe.Run(context.Background(), newExample())
+
+	var perr *image.ProcessorError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Run() should return a %T; got %T", perr, err)
+	}
+
+	if perr.Stage != 1 {
+		t.Fatalf("error should be reported for stage %d; got %d", 1, perr.Stage)
+	}
+
+	if perr.Name != "image.ProcessorFunc" {
+		t.Fatalf("error should report processor name %q; got %q", "image.ProcessorFunc", perr.Name)
+	}
+
+	if !perr.Tags.Contains("size=sm") {
+		t.Fatalf("error should report the tags of the input image; got %v", perr.Tags)
+	}
+
+	if !errors.Is(err, mockError) {
+		t.Fatalf("error should wrap %q; got %q", mockError, err)
+	}
+
+	want := "stage 1: image.ProcessorFunc processor [resized size=sm]: mock error"
+	if err.Error() != want {
+		t.Fatalf("error message should be %q; got %q", want, err.Error())
+	}
+}
+
+func TestProcessorError_multipleOriginals(t *testing.T) {
+	pipe := image.Pipeline{
+		image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+			return []image.Processed{ctx.Image(), ctx.Image()}, nil
+		}),
+	}
+
+	_, err := pipe.Run(context.Background(), newExample())
+
+	if !errors.Is(err, image.ErrMultipleOriginals) {
+		t.Fatalf("Run() should fail with %q; got %q", image.ErrMultipleOriginals, err)
+	}
+
+	var perr *image.ProcessorError
+	if !errors.As(err, &perr) || perr.Stage != 0 {
+		t.Fatalf("Run() should return a %T for stage %d; got %v", perr, 0, err)
+	}
+}
+
+type namedProcessor struct{ image.ProcessorFunc }
+
+func (namedProcessor) Name() string { return "named" }
+
+func TestProcessorName(t *testing.T) {
+	if name := image.ProcessorName(image.Resize(image.DimensionList{})); name != "*image.Resizer" {
+		t.Fatalf("ProcessorName() should return %q; got %q", "*image.Resizer", name)
+	}
+
+	if name := image.ProcessorName(namedProcessor{}); name != "named" {
+		t.Fatalf("ProcessorName() should return %q; got %q", "named", name)
+	}
+}
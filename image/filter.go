@@ -0,0 +1,314 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+var _ Processor = (*Filter)(nil)
+
+var (
+	// ErrUnknownFilter is returned when parsing a filter whose name is not
+	// registered.
+	ErrUnknownFilter = errors.New("unknown filter")
+
+	// ErrInvalidFilter is returned when parsing a filter with an invalid
+	// value.
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// FilterKey is the key of the structured tag that is assigned to filtered
+// images, e.g. "filter=grayscale" or "filter=brightness,value=10".
+const FilterKey = "filter"
+
+// Filter is a [Processor] that applies a color adjustment or effect to
+// images. Filtered images keep the tags of their input image and are
+// additionally tagged with a "filter=" tag. When multiple Filters are chained
+// in a [Pipeline], the images have a "filter=" tag for each of them.
+//
+// Filters are expressible by name, so they can be configured declaratively;
+// see [ParseFilter].
+type Filter struct {
+	name  string
+	value string
+	fn    func(image.Image) *image.NRGBA
+}
+
+// NewFilter returns a [*Filter] with the given name that applies fn to images.
+// value is the value of the filter as it appears in its spec and tag, or an
+// empty string if the filter has no value. NewFilter panics if fn is nil.
+func NewFilter(name, value string, fn func(image.Image) *image.NRGBA) *Filter {
+	if fn == nil {
+		panic(fmt.Sprintf("image: NewFilter(%q) called with a nil function", name))
+	}
+	return &Filter{name: name, value: value, fn: fn}
+}
+
+// Grayscale returns a [*Filter] that converts images to grayscale.
+func Grayscale() *Filter {
+	return NewFilter("grayscale", "", imaging.Grayscale)
+}
+
+// Sepia returns a [*Filter] that applies a sepia tone to images.
+func Sepia() *Filter {
+	return NewFilter("sepia", "", func(img image.Image) *image.NRGBA {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			r, g, b := float64(c.R), float64(c.G), float64(c.B)
+			return color.NRGBA{
+				R: clampUint8(0.393*r + 0.769*g + 0.189*b),
+				G: clampUint8(0.349*r + 0.686*g + 0.168*b),
+				B: clampUint8(0.272*r + 0.534*g + 0.131*b),
+				A: c.A,
+			}
+		})
+	})
+}
+
+// Brightness returns a [*Filter] that changes the brightness of images by the
+// given percentage, between -100 and 100.
+func Brightness(percentage float64) *Filter {
+	return NewFilter("brightness", formatFilterValue(percentage), func(img image.Image) *image.NRGBA {
+		return imaging.AdjustBrightness(img, percentage)
+	})
+}
+
+// Contrast returns a [*Filter] that changes the contrast of images by the
+// given percentage, between -100 and 100.
+func Contrast(percentage float64) *Filter {
+	return NewFilter("contrast", formatFilterValue(percentage), func(img image.Image) *image.NRGBA {
+		return imaging.AdjustContrast(img, percentage)
+	})
+}
+
+// Saturation returns a [*Filter] that changes the saturation of images by the
+// given percentage, between -100 and 100.
+func Saturation(percentage float64) *Filter {
+	return NewFilter("saturation", formatFilterValue(percentage), func(img image.Image) *image.NRGBA {
+		return imaging.AdjustSaturation(img, percentage)
+	})
+}
+
+// Gamma returns a [*Filter] that applies gamma correction to images. Values
+// below 1 darken images, values above 1 brighten them.
+func Gamma(gamma float64) *Filter {
+	return NewFilter("gamma", formatFilterValue(gamma), func(img image.Image) *image.NRGBA {
+		return imaging.AdjustGamma(img, gamma)
+	})
+}
+
+// Blur returns a [*Filter] that applies a Gaussian blur with the given
+// standard deviation, in pixels, to images.
+func Blur(sigma float64) *Filter {
+	return NewFilter("blur", formatFilterValue(sigma), func(img image.Image) *image.NRGBA {
+		return imaging.Blur(img, sigma)
+	})
+}
+
+func formatFilterValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Name returns the name of the filter, e.g. "grayscale". Name returns an
+// empty string for a nil filter.
+func (f *Filter) Name() string {
+	if f == nil {
+		return ""
+	}
+	return f.name
+}
+
+// String returns the spec of the filter, as parsed by [ParseFilter], e.g.
+// "grayscale" or "brightness=10".
+func (f *Filter) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+	if f.value == "" {
+		return f.name
+	}
+	return f.name + "=" + f.value
+}
+
+// Tag returns the tag that is assigned to filtered images.
+func (f *Filter) Tag() string {
+	if f.value == "" {
+		return FormatTag(Attr(FilterKey, f.name))
+	}
+	return FormatTag(Attr(FilterKey, f.name), Attr("value", f.value))
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (f *Filter) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (f *Filter) UnmarshalText(text []byte) error {
+	parsed, err := ParseFilter(string(text))
+	if err != nil {
+		return err
+	}
+	*f = *parsed
+	return nil
+}
+
+// Process implements [Processor]. Process fails with [ErrInvalidFilter] if
+// the filter was not created by [NewFilter] or one of the filter constructors,
+// e.g. a nil or zero-value Filter.
+func (f *Filter) Process(ctx ProcessorContext) ([]Processed, error) {
+	if f == nil {
+		return nil, fmt.Errorf("%w: nil filter", ErrInvalidFilter)
+	}
+
+	if f.fn == nil {
+		return nil, fmt.Errorf("%w: filter %q has no function", ErrInvalidFilter, f.name)
+	}
+
+	input := ctx.Image()
+	out := input.Derive(f.fn(input.Image))
+	out.Tags = out.Tags.With(f.Tag())
+	return []Processed{out}, nil
+}
+
+// FilterFactory creates a [*Filter] from the value of a filter spec. value is
+// empty if the spec has no value.
+type FilterFactory func(value string) (*Filter, error)
+
+var filters = struct {
+	sync.RWMutex
+	factories map[string]FilterFactory
+}{factories: map[string]FilterFactory{
+	"grayscale":  noValueFilter(Grayscale),
+	"sepia":      noValueFilter(Sepia),
+	"brightness": floatFilter(Brightness, percentage),
+	"contrast":   floatFilter(Contrast, percentage),
+	"saturation": floatFilter(Saturation, percentage),
+	"gamma":      floatFilter(Gamma, positive),
+	"blur":       floatFilter(Blur, nonNegative),
+}}
+
+func noValueFilter(fn func() *Filter) FilterFactory {
+	return func(value string) (*Filter, error) {
+		if value != "" {
+			return nil, fmt.Errorf("%w: unexpected value %q", ErrInvalidFilter, value)
+		}
+		return fn(), nil
+	}
+}
+
+// floatFilter returns a FilterFactory for filters with a numeric value. check
+// returns a description of the valid values if v is invalid.
+func floatFilter(fn func(float64) *Filter, check func(v float64) (string, bool)) FilterFactory {
+	return func(value string) (*Filter, error) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: %q is not a finite number", ErrInvalidFilter, value)
+		}
+		if valid, ok := check(v); !ok {
+			return nil, fmt.Errorf("%w: value must be %s; got %s", ErrInvalidFilter, valid, value)
+		}
+		return fn(v), nil
+	}
+}
+
+func percentage(v float64) (string, bool) {
+	return "between -100 and 100", v >= -100 && v <= 100
+}
+
+func positive(v float64) (string, bool) {
+	return "greater than 0", v > 0
+}
+
+func nonNegative(v float64) (string, bool) {
+	return "at least 0", v >= 0
+}
+
+// RegisterFilter registers a filter under the given name, so that it can be
+// parsed by [ParseFilter]. Registering a filter with the name of an existing
+// filter replaces the existing filter.
+func RegisterFilter(name string, factory FilterFactory) {
+	filters.Lock()
+	defer filters.Unlock()
+	filters.factories[name] = factory
+}
+
+// FilterNames returns the names of all registered filters, sorted
+// lexicographically.
+func FilterNames() []string {
+	filters.RLock()
+	defer filters.RUnlock()
+
+	names := make([]string, 0, len(filters.factories))
+	for name := range filters.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ParseFilter parses a filter spec of the form "name" or "name=value", e.g.
+// "grayscale", "brightness=10" or "blur=1.5". The following filters are
+// built in:
+//
+//	grayscale          see [Grayscale]
+//	sepia              see [Sepia]
+//	brightness=<pct>   see [Brightness]
+//	contrast=<pct>     see [Contrast]
+//	saturation=<pct>   see [Saturation]
+//	gamma=<gamma>      see [Gamma]
+//	blur=<sigma>       see [Blur]
+//
+// Additional filters can be registered using [RegisterFilter].
+func ParseFilter(spec string) (*Filter, error) {
+	name, value, _ := strings.Cut(strings.TrimSpace(spec), "=")
+	name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+
+	filters.RLock()
+	factory, ok := filters.factories[name]
+	filters.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("parse filter %q: %w", spec, ErrUnknownFilter)
+	}
+
+	f, err := factory(value)
+	if err != nil {
+		return nil, fmt.Errorf("parse filter %q: %w", spec, err)
+	}
+
+	if f == nil {
+		return nil, fmt.Errorf("parse filter %q: %w: factory returned no filter", spec, ErrInvalidFilter)
+	}
+
+	return f, nil
+}
+
+// ParseFilters parses a comma-separated list of filter specs into a
+// [Pipeline] that applies the filters in order, e.g.
+// "grayscale, contrast=20, blur=0.5". See [ParseFilter] for the spec format.
+func ParseFilters(specs string) (Pipeline, error) {
+	var out Pipeline
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		f, err := ParseFilter(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, f)
+	}
+	return out, nil
+}
@@ -0,0 +1,216 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modernice/media-tools/image"
+)
+
+func applyFilter(t *testing.T, proc image.Processor, c color.NRGBA) image.Processed {
+	t.Helper()
+
+	result, err := image.Pipeline{proc}.Run(context.Background(), newUniform(10, 10, c))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	return result.Images[0]
+}
+
+func TestFilter_Process(t *testing.T) {
+	orange := color.NRGBA{200, 120, 40, 255}
+
+	tests := []struct {
+		filter *image.Filter
+		tag    string
+		check  func(color.NRGBA) bool
+	}{
+		{image.Grayscale(), "filter=grayscale", func(c color.NRGBA) bool { return c.R == c.G && c.G == c.B }},
+		{image.Sepia(), "filter=sepia", func(c color.NRGBA) bool { return c.R > c.G && c.G > c.B && c.R != orange.R }},
+		{image.Brightness(20), "filter=brightness,value=20", func(c color.NRGBA) bool { return c.R > orange.R && c.B > orange.B }},
+		{image.Brightness(-20), "filter=brightness,value=-20", func(c color.NRGBA) bool { return c.R < orange.R && c.B < orange.B }},
+		{image.Contrast(50), "filter=contrast,value=50", func(c color.NRGBA) bool { return c.R > orange.R && c.B < orange.B }},
+		{image.Saturation(-100), "filter=saturation,value=-100", func(c color.NRGBA) bool { return c.R == c.G && c.G == c.B }},
+		{image.Gamma(2), "filter=gamma,value=2", func(c color.NRGBA) bool { return c.G > orange.G }},
+		{image.Blur(1), "filter=blur,value=1", func(c color.NRGBA) bool { return c == orange }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter.String(), func(t *testing.T) {
+			img := applyFilter(t, tt.filter, orange)
+
+			if c := pixelAt(img.Image, 5, 5); !tt.check(c) {
+				t.Fatalf("unexpected color %v after applying %q to %v", c, tt.filter, orange)
+			}
+
+			if !img.Tags.Contains(tt.tag) {
+				t.Fatalf("filtered image should have tag %q; got %v", tt.tag, img.Tags)
+			}
+
+			if !img.Original {
+				t.Fatalf("filtered image should keep the %q flag", image.Original)
+			}
+		})
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	pipe, err := image.ParseFilters("grayscale, Contrast=20, blur=0.5")
+	if err != nil {
+		t.Fatalf("parse filters: %v", err)
+	}
+
+	if len(pipe) != 3 {
+		t.Fatalf("expected 3 filters; got %d", len(pipe))
+	}
+
+	result, err := pipe.Run(context.Background(), newUniform(10, 10, color.NRGBA{200, 120, 40, 255}))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	img := result.Images[0]
+
+	want := []string{"grayscale", "contrast", "blur"}
+	if diff := cmp.Diff(want, img.Tags.Values(image.FilterKey)); diff != "" {
+		t.Fatalf("filtered image should have filters %v; (-want +got):\n%s", want, diff)
+	}
+
+	if c := pixelAt(img.Image, 5, 5); c.R != c.G || c.G != c.B {
+		t.Fatalf("filtered image should be gray; got %v", c)
+	}
+
+	for _, p := range result.Lineage {
+		if p.Processor != "" && p.Processor != "grayscale" && p.Processor != "contrast" && p.Processor != "blur" {
+			t.Fatalf("filters should be named after their spec; got %q", p.Processor)
+		}
+	}
+}
+
+func TestParseFilter_invalid(t *testing.T) {
+	tests := map[string]error{
+		"unknown":         image.ErrUnknownFilter,
+		"brightness":      image.ErrInvalidFilter,
+		"brightness=much": image.ErrInvalidFilter,
+		"grayscale=50":    image.ErrInvalidFilter,
+		"brightness=NaN":  image.ErrInvalidFilter,
+		"contrast=Inf":    image.ErrInvalidFilter,
+		"contrast=-inf":   image.ErrInvalidFilter,
+		"brightness=101":  image.ErrInvalidFilter,
+		"saturation=-150": image.ErrInvalidFilter,
+		"gamma=0":         image.ErrInvalidFilter,
+		"gamma=-1":        image.ErrInvalidFilter,
+		"blur=-0.5":       image.ErrInvalidFilter,
+		"":                image.ErrUnknownFilter,
+	}
+
+	for spec, want := range tests {
+		if _, err := image.ParseFilter(spec); !errors.Is(err, want) {
+			t.Fatalf("ParseFilter(%q) should fail with %q; got %v", spec, want, err)
+		}
+	}
+
+	for _, spec := range []string{"brightness=-100", "saturation=100", "gamma=0.1", "blur=0"} {
+		if _, err := image.ParseFilter(spec); err != nil {
+			t.Fatalf("ParseFilter(%q) should accept the limit of the valid range; got %v", spec, err)
+		}
+	}
+}
+
+func TestFilter_UnmarshalText(t *testing.T) {
+	var f image.Filter
+	if err := f.UnmarshalText([]byte("gamma=1.5")); err != nil {
+		t.Fatalf("unmarshal filter: %v", err)
+	}
+
+	text, err := f.MarshalText()
+	if err != nil {
+		t.Fatalf("marshal filter: %v", err)
+	}
+
+	if string(text) != "gamma=1.5" {
+		t.Fatalf("MarshalText() should return %q; got %q", "gamma=1.5", text)
+	}
+}
+
+func TestFilter_Process_zeroValue(t *testing.T) {
+	_, err := image.Pipeline{&image.Filter{}}.Run(context.Background(), newUniform(10, 10, color.Black))
+	if !errors.Is(err, image.ErrInvalidFilter) {
+		t.Fatalf("zero-value Filter should fail with %q; got %v", image.ErrInvalidFilter, err)
+	}
+}
+
+func TestFilter_Process_nil(t *testing.T) {
+	var f *image.Filter
+	_, err := image.Pipeline{f}.Run(context.Background(), newUniform(10, 10, color.Black))
+	if !errors.Is(err, image.ErrInvalidFilter) {
+		t.Fatalf("nil Filter should fail with %q; got %v", image.ErrInvalidFilter, err)
+	}
+}
+
+func TestParseFilter_nilFactoryResult(t *testing.T) {
+	image.RegisterFilter("nothing", func(string) (*image.Filter, error) {
+		return nil, nil
+	})
+
+	if _, err := image.ParseFilters("grayscale, nothing"); !errors.Is(err, image.ErrInvalidFilter) {
+		t.Fatalf("filter whose factory returns no filter should fail with %q; got %v", image.ErrInvalidFilter, err)
+	}
+}
+
+func TestNewFilter_nilFunc(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("NewFilter() should panic if fn is nil")
+		}
+	}()
+
+	image.NewFilter("noop", "", nil)
+}
+
+func TestRegisterFilter(t *testing.T) {
+	image.RegisterFilter("invert", func(value string) (*image.Filter, error) {
+		return image.NewFilter("invert", "", func(img stdimage.Image) *stdimage.NRGBA {
+			out := stdimage.NewNRGBA(img.Bounds())
+			for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+				for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+					c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+					out.SetNRGBA(x, y, color.NRGBA{255 - c.R, 255 - c.G, 255 - c.B, c.A})
+				}
+			}
+			return out
+		}), nil
+	})
+
+	f, err := image.ParseFilter("invert")
+	if err != nil {
+		t.Fatalf("parse registered filter: %v", err)
+	}
+
+	img := applyFilter(t, f, color.NRGBA{200, 120, 40, 255})
+
+	if c := pixelAt(img.Image, 0, 0); c != (color.NRGBA{55, 135, 215, 255}) {
+		t.Fatalf("registered filter should be applied; got %v", c)
+	}
+
+	if !img.Tags.Contains("filter=invert") {
+		t.Fatalf("filtered image should have tag %q; got %v", "filter=invert", img.Tags)
+	}
+
+	found := false
+	for _, name := range image.FilterNames() {
+		found = found || name == "invert"
+	}
+	if !found {
+		t.Fatalf("FilterNames() should contain %q; got %v", "invert", image.FilterNames())
+	}
+}
@@ -0,0 +1,115 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoMatch is returned by [PipelineResult.Single] if no image matches
+	// the query.
+	ErrNoMatch = errors.New("no image matches")
+
+	// ErrMultipleMatches is returned by [PipelineResult.Single] if more than
+	// one image matches the query.
+	ErrMultipleMatches = errors.New("multiple images match")
+)
+
+// Group is a group of processed images that share the same key.
+type Group struct {
+	Key    string
+	Images []Processed
+}
+
+// Groups is an ordered list of [Group]s. The groups are ordered by the first
+// appearance of their key.
+type Groups []Group
+
+// Keys returns the keys of the groups, in order.
+func (groups Groups) Keys() []string {
+	out := make([]string, len(groups))
+	for i, g := range groups {
+		out[i] = g.Key
+	}
+	return out
+}
+
+// Get returns the group with the given key.
+func (groups Groups) Get(key string) (Group, bool) {
+	for _, g := range groups {
+		if g.Key == key {
+			return g, true
+		}
+	}
+	return Group{}, false
+}
+
+// GroupBy groups the processed images by the key that is returned by fn for
+// the tags of each image. Images for which fn returns an empty key are not
+// part of any group. [DimensionName] and [CompressionName] can be used as fn
+// to group by size or compression.
+func (result PipelineResult) GroupBy(fn func(Tags) string) Groups {
+	return groupBy(result.Images, fn)
+}
+
+// GroupBySize groups the processed images by their dimension name, as
+// returned by [DimensionName].
+func (result PipelineResult) GroupBySize() Groups {
+	return result.GroupBy(DimensionName)
+}
+
+// GroupByCompression groups the processed images by their compression name,
+// as returned by [CompressionName].
+func (result PipelineResult) GroupByCompression() Groups {
+	return result.GroupBy(CompressionName)
+}
+
+// GroupBy groups the images of the group by the key that is returned by fn,
+// like [PipelineResult.GroupBy]. GroupBy can be used to build nested groups,
+// e.g. by size and then by compression.
+func (g Group) GroupBy(fn func(Tags) string) Groups {
+	return groupBy(g.Images, fn)
+}
+
+func groupBy(images []Processed, fn func(Tags) string) Groups {
+	var out Groups
+	index := make(map[string]int)
+
+	for _, img := range images {
+		key := fn(img.Tags)
+		if key == "" {
+			continue
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, Group{Key: key})
+		}
+
+		out[i].Images = append(out[i].Images, img)
+	}
+
+	return out
+}
+
+// Single returns the only processed image that matches the given query
+// expression (see [Query]). If no image matches, an error that wraps
+// [ErrNoMatch] is returned. If multiple images match, an error that wraps
+// [ErrMultipleMatches] is returned.
+func (result PipelineResult) Single(expr string) (Processed, error) {
+	images, err := result.Query(expr)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	switch len(images) {
+	case 0:
+		return Processed{}, fmt.Errorf("query %q: %w", expr, ErrNoMatch)
+	case 1:
+		return images[0], nil
+	default:
+		return Processed{}, fmt.Errorf("query %q: %w (%d images)", expr, ErrMultipleMatches, len(images))
+	}
+}
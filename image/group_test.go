@@ -0,0 +1,91 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestPipelineResult_GroupBy(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}, "lg": {960}}),
+		image.CompressMany([]image.Compression{compression.JPEG(80), compression.JPEG(50)}),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	sizes := result.GroupBySize()
+
+	if want := []string{"sm", "md", "lg"}; !cmp.Equal(want, sizes.Keys()) {
+		t.Fatalf("GroupBySize() returned wrong keys\n%s", cmp.Diff(want, sizes.Keys()))
+	}
+
+	for _, size := range sizes {
+		if len(size.Images) != 2 {
+			t.Fatalf("group %q should contain 2 images; got %d", size.Key, len(size.Images))
+		}
+
+		qualities := size.GroupBy(func(tags image.Tags) string {
+			return image.FormatTag(image.Attr("quality", tags.Attributes(image.CompressionKey)[image.QualityKey]))
+		})
+
+		if want := []string{"quality=80", "quality=50"}; !cmp.Equal(want, qualities.Keys()) {
+			t.Fatalf("nested GroupBy() returned wrong keys\n%s", cmp.Diff(want, qualities.Keys()))
+		}
+
+		for _, q := range qualities {
+			if len(q.Images) != 1 || image.DimensionName(q.Images[0].Tags) != size.Key {
+				t.Fatalf("nested group %q should contain the single %q image", q.Key, size.Key)
+			}
+		}
+	}
+
+	compressions := result.GroupByCompression()
+	if want := []string{"jpeg"}; !cmp.Equal(want, compressions.Keys()) {
+		t.Fatalf("GroupByCompression() returned wrong keys\n%s", cmp.Diff(want, compressions.Keys()))
+	}
+
+	if g, ok := compressions.Get("jpeg"); !ok || len(g.Images) != 6 {
+		t.Fatalf("group %q should contain 6 images", "jpeg")
+	}
+
+	if _, ok := compressions.Get("png"); ok {
+		t.Fatalf("Get() should not return a group for unknown keys")
+	}
+}
+
+func TestPipelineResult_Single(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}}),
+		image.CompressMany([]image.Compression{compression.JPEG(80), compression.JPEG(50)}),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	img, err := result.Single("size=md AND quality=50")
+	if err != nil {
+		t.Fatalf("Single() failed: %v", err)
+	}
+
+	if image.DimensionName(img.Tags) != "md" || image.CompressionQuality(img.Tags) != 50 {
+		t.Fatalf("Single() returned the wrong image: %v", img.Tags)
+	}
+
+	if _, err := result.Single("size=xl"); !errors.Is(err, image.ErrNoMatch) {
+		t.Fatalf("Single() should fail with %q; got %q", image.ErrNoMatch, err)
+	}
+
+	if _, err := result.Single("size=md"); !errors.Is(err, image.ErrMultipleMatches) {
+		t.Fatalf("Single() should fail with %q; got %q", image.ErrMultipleMatches, err)
+	}
+}
@@ -1,8 +1,11 @@
 package image
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 )
 
 // Dimensions are the width and height of an image, in pixels.
@@ -56,24 +59,50 @@ func (dl DimensionList) Dimensions() []Dimensions {
 	return dl
 }
 
-// DimensionMap provides named [Dimensions].
+// DimensionMap provides named [Dimensions]. Multiple names may share the same
+// [Dimensions]; such names are aliases of each other.
 type DimensionMap map[string]Dimensions
 
-// Dimensions returns the dimension list.
+// Dimensions returns the unique dimensions of the map, sorted by width and
+// then by height.
 func (dm DimensionMap) Dimensions() []Dimensions {
 	dims := make([]Dimensions, 0, len(dm))
 	for _, dim := range dm {
 		dims = append(dims, dim)
 	}
-	return dims
+	return sortDimensions(dims)
 }
 
-// Tag returns the configured tag for the given [Dimensions].
+// Tag returns the configured tag for the given [Dimensions]. If multiple names
+// share the same [Dimensions], the lexicographically smallest name is
+// returned.
 func (dm DimensionMap) Tag(dim Dimensions) string {
-	for tag, d := range dm {
+	if names := dm.Names(dim); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// Names returns all names of the given [Dimensions], sorted lexicographically.
+func (dm DimensionMap) Names(dim Dimensions) []string {
+	var names []string
+	for name, d := range dm {
 		if d == dim {
-			return tag
+			names = append(names, name)
 		}
 	}
-	return ""
+	sort.Strings(names)
+	return names
+}
+
+// sortDimensions sorts dims by width and then by height and removes
+// duplicates. dims is sorted in place.
+func sortDimensions(dims []Dimensions) []Dimensions {
+	slices.SortFunc(dims, func(a, b Dimensions) int {
+		if a.Width() != b.Width() {
+			return cmp.Compare(a.Width(), b.Width())
+		}
+		return cmp.Compare(a.Height(), b.Height())
+	})
+	return slices.Compact(dims)
 }
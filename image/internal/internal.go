@@ -38,3 +38,10 @@ func SizeOf(img image.Image) (int, error) {
 	}
 	return buf.Len(), nil
 }
+
+// EqualPixels returns whether two images have the same bounds and exactly the
+// same pixels.
+func EqualPixels(a, b image.Image) bool {
+	na, nb := ToNRGBA(a), ToNRGBA(b)
+	return na.Bounds() == nb.Bounds() && bytes.Equal(na.Pix, nb.Pix)
+}
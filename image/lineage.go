@@ -0,0 +1,185 @@
+package image
+
+import (
+	"image"
+	"reflect"
+	"sort"
+)
+
+// ImageID identifies an image within a [Pipeline] run. The zero ImageID
+// identifies no image.
+type ImageID uint64
+
+// Provenance describes how an image was produced within a [Pipeline] run.
+type Provenance struct {
+	// ID is the ID of the image.
+	ID ImageID
+
+	// Parent is the ID of the image that the image was derived from. Parent
+	// is zero for the original image.
+	Parent ImageID
+
+	// Stage is the index of the stage that produced the image, or -1 for the
+	// original image. For images that were produced by a nested [Pipeline],
+	// e.g. a [Branch], Stage is the index within the nested Pipeline.
+	Stage int
+
+	// Path is the indexes of the stages that led to the stage that produced
+	// the image, starting at the top-level [Pipeline] and ending with Stage.
+	// Images produced by the top-level Pipeline have a Path of length 1,
+	// images produced by a [Branch] of stage 2 have a Path of [2, Stage].
+	// Path is empty for the original image.
+	Path []int
+
+	// Processor is the name of the [Processor] that produced the image, as
+	// returned by [ProcessorName]. Processor is empty for the original image.
+	Processor string
+
+	// Tags are the tags of the image at the time it was produced.
+	Tags Tags
+}
+
+// Derive returns a new image that is derived from p. The returned image has
+// the tags, metadata and Original flag of p, but no ID, so that the
+// [Pipeline] records it as a new image whose parent is p. The metadata that
+// describes the encoding of p ([MetaCompression], [MetaQuality] and
+// [MetaBytes]) is not carried over. Processors that
+// transform their input image should use Derive instead of modifying the
+// Image of their input.
+func (p Processed) Derive(img image.Image) Processed {
+	return Processed{
+		Image:    img,
+		Tags:     p.Tags,
+		Meta:     p.Meta.withBounds(img),
+		Original: p.Original,
+	}
+}
+
+// lineage records the provenance of the images of a Pipeline run. Nested
+// pipelines share the lineage of their parent.
+type lineage struct {
+	lastID ImageID
+	nodes  map[ImageID]Provenance
+}
+
+func newLineage() *lineage {
+	return &lineage{nodes: make(map[ImageID]Provenance)}
+}
+
+// root assigns an ID to the input image of a Pipeline if it has none.
+func (l *lineage) root(input Processed) Processed {
+	if input.ID != 0 {
+		return input
+	}
+
+	l.lastID++
+	input.ID = l.lastID
+	l.nodes[input.ID] = Provenance{
+		ID:    input.ID,
+		Stage: -1,
+		Tags:  input.Tags,
+	}
+
+	return input
+}
+
+// derive assigns IDs to all images that a Processor produced. path is the
+// path of the stages that enclose the stage. Images that have the ID and the
+// Image of the input are images that the Processor passed through; images
+// that have another ID were recorded by a nested Pipeline.
+func (l *lineage) derive(path []int, stage Stage, input Processed, processed []Processed) {
+	name := ProcessorName(stage.Processor)
+
+	for i, pimg := range processed {
+		if pimg.ID != 0 && (pimg.ID != input.ID || sameImage(pimg.Image, input.Image)) {
+			continue
+		}
+
+		l.lastID++
+		pimg.ID = l.lastID
+		pimg.Parent = input.ID
+		pimg.Chain = append(append(make([]string, 0, len(input.Chain)+1), input.Chain...), name)
+
+		l.nodes[pimg.ID] = Provenance{
+			ID:        pimg.ID,
+			Parent:    pimg.Parent,
+			Stage:     stage.Index,
+			Path:      append(append(make([]int, 0, len(path)+1), path...), stage.Index),
+			Processor: name,
+			Tags:      pimg.Tags,
+		}
+
+		processed[i] = pimg
+	}
+}
+
+// sameImage reports whether a and b are the same image.
+func sameImage(a, b image.Image) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if t := reflect.TypeOf(a); t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// Provenance returns the [Provenance] of the image with the given ID.
+func (result PipelineResult) Provenance(id ImageID) (Provenance, bool) {
+	p, ok := result.Lineage[id]
+	return p, ok
+}
+
+// Ancestors returns the [Provenance] of all images that the given image was
+// derived from, starting with its parent and ending with the original image.
+func (result PipelineResult) Ancestors(img Processed) []Provenance {
+	var out []Provenance
+	for id := img.Parent; id != 0; {
+		p, ok := result.Lineage[id]
+		if !ok {
+			break
+		}
+		out = append(out, p)
+		id = p.Parent
+	}
+	return out
+}
+
+// DerivedFrom returns the processed images that were derived from the image
+// with the given ID, directly or indirectly. The image itself is not returned.
+func (result PipelineResult) DerivedFrom(id ImageID) []Processed {
+	var out []Processed
+	for _, img := range result.Images {
+		for parent := img.Parent; parent != 0; parent = result.Lineage[parent].Parent {
+			if parent == id {
+				out = append(out, img)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FindProvenance returns the [Provenance] of all images of the [Pipeline] run,
+// including intermediate images that are not part of the result, that had at
+// least 1 of the given tags when they were produced. The provenances are
+// returned in the order the images were produced.
+func (result PipelineResult) FindProvenance(tags ...string) []Provenance {
+	ids := make([]ImageID, 0, len(result.Lineage))
+	for id := range result.Lineage {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var out []Provenance
+	for _, id := range ids {
+		p := result.Lineage[id]
+		for _, tag := range tags {
+			if p.Tags.Contains(tag) {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
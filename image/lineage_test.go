@@ -0,0 +1,175 @@
+package image_test
+
+import (
+	"context"
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestPipelineResult_Lineage(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}}),
+		image.CompressMany([]image.Compression{compression.JPEG(75), compression.JPEG(50)}),
+		image.Tag(image.NewTags("foo")),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	org, _ := result.Original()
+	if org.ID == 0 || org.Parent != 0 || len(org.Chain) != 0 {
+		t.Fatalf("original image should have an ID, no parent and an empty chain; got id=%d parent=%d chain=%v", org.ID, org.Parent, org.Chain)
+	}
+
+	ids := make(map[image.ImageID]bool)
+	for _, img := range result.Images {
+		if ids[img.ID] {
+			t.Fatalf("image IDs should be unique; %d is assigned twice", img.ID)
+		}
+		ids[img.ID] = true
+	}
+
+	// original + 2 resized + 4 compressed
+	if len(result.Lineage) != 7 {
+		t.Fatalf("lineage should contain 7 images; got %d", len(result.Lineage))
+	}
+
+	md := result.FindProvenance("size=md")
+	if len(md) != 3 {
+		t.Fatalf("expected 3 provenances with tag %q (1 resized + 2 compressed); got %d", "size=md", len(md))
+	}
+
+	if md[0].Processor != "*image.Resizer" || md[0].Parent != org.ID {
+		t.Fatalf("first %q provenance should be the resized image; got %+v", "size=md", md[0])
+	}
+
+	derived := result.DerivedFrom(md[0].ID)
+	if len(derived) != 2 {
+		t.Fatalf("expected 2 images derived from the %q resize; got %d", "size=md", len(derived))
+	}
+
+	for _, img := range derived {
+		if !img.Tags.Contains(image.Compressed) || !img.Tags.Contains("size=md") {
+			t.Fatalf("derived images should be compressed %q images; got %v", "size=md", img.Tags)
+		}
+
+		if img.Parent != md[0].ID {
+			t.Fatalf("derived image should have parent %d; got %d", md[0].ID, img.Parent)
+		}
+
+		want := []string{"*image.Resizer", "*image.Compressor"}
+		if len(img.Chain) != len(want) || img.Chain[0] != want[0] || img.Chain[1] != want[1] {
+			t.Fatalf("derived image should have chain %v; got %v", want, img.Chain)
+		}
+
+		ancestors := result.Ancestors(img)
+		if len(ancestors) != 2 || ancestors[0].ID != md[0].ID || ancestors[1].ID != org.ID {
+			t.Fatalf("derived image should have ancestors [%d %d]; got %+v", md[0].ID, org.ID, ancestors)
+		}
+	}
+
+	if all := result.DerivedFrom(org.ID); len(all) != len(result.Images)-1 {
+		t.Fatalf("all images except the original should be derived from the original; got %d", len(all))
+	}
+}
+
+func TestPipelineResult_Lineage_Branch(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionList{{640}}, image.DiscardInput(true)),
+		image.Branch(
+			image.Pipeline{image.Tag(image.NewTags("a"))},
+			image.Pipeline{image.Compress(compression.JPEG(50))},
+			image.Pipeline{image.Tag(image.NewTags("c"))},
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 3 {
+		t.Fatalf("expected 3 images; got %d", len(result.Images))
+	}
+
+	resized := result.FindProvenance("resized")[0]
+
+	ids := make(map[image.ImageID]bool)
+	for _, img := range result.Images {
+		if ids[img.ID] {
+			t.Fatalf("image IDs should be unique; %d is assigned twice", img.ID)
+		}
+		ids[img.ID] = true
+
+		if img.ID != resized.ID && img.Parent != resized.ID {
+			t.Fatalf("branch images should be derived from the resized image %d; got parent %d", resized.ID, img.Parent)
+		}
+	}
+}
+
+func TestPipelineResult_Lineage_swappedImage(t *testing.T) {
+	swap := image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+		out := ctx.Image()
+		out.Image = newUniform(10, 10, color.Black)
+		return []image.Processed{out}, nil
+	})
+
+	passThrough := image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+		return []image.Processed{ctx.Image()}, nil
+	})
+
+	result, err := image.Pipeline{swap, passThrough}.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	img := result.Images[0]
+
+	p, ok := result.Provenance(img.ID)
+	if !ok || p.Stage != 0 || p.Parent == 0 {
+		t.Fatalf("image with a swapped Image should be recorded as a new image of stage 0; got %+v", p)
+	}
+
+	if len(result.Lineage) != 2 {
+		t.Fatalf("lineage should contain 2 images; got %d", len(result.Lineage))
+	}
+}
+
+func TestPipelineResult_Lineage_path(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionList{{640}}, image.DiscardInput(true)),
+		image.Branch(
+			image.Pipeline{image.Tag(image.NewTags("a"))},
+			image.Pipeline{image.Compress(compression.JPEG(50))},
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	resized := result.FindProvenance("resized")[0]
+	if diff := cmp.Diff([]int{0}, resized.Path); diff != "" {
+		t.Fatalf("resized image should have path [0]; (-want +got):\n%s", diff)
+	}
+
+	compressed := result.FindProvenance(image.Compressed)[0]
+	if compressed.Stage != 0 {
+		t.Fatalf("compressed image should be produced by stage 0 of its branch; got %d", compressed.Stage)
+	}
+	if diff := cmp.Diff([]int{1, 0}, compressed.Path); diff != "" {
+		t.Fatalf("compressed image should have path [1 0]; (-want +got):\n%s", diff)
+	}
+
+	org, _ := result.Provenance(resized.Parent)
+	if len(org.Path) != 0 {
+		t.Fatalf("original image should have an empty path; got %v", org.Path)
+	}
+}
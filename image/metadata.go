@@ -0,0 +1,133 @@
+package image
+
+import "image"
+
+// Keys of the [Metadata] that is populated by the built-in Processors.
+const (
+	// MetaWidth is the width of an image, in pixels (int).
+	MetaWidth = "width"
+
+	// MetaHeight is the height of an image, in pixels (int).
+	MetaHeight = "height"
+
+	// MetaSize is the name of the [Dimensions] that an image was resized to
+	// (string). Only set if the [Resizer] uses a [DimensionMap].
+	MetaSize = "size"
+
+	// MetaDensity is the pixel density of a resized image (float64). Only set
+	// if the [Resizer] is configured with [Densities].
+	MetaDensity = "density"
+
+	// MetaCompression is the name of the [Compression] that compressed an
+	// image (string).
+	MetaCompression = "compression"
+
+	// MetaQuality is the quality of the [Compression] that compressed an
+	// image (int).
+	MetaQuality = "quality"
+
+	// MetaBytes is the size of the encoded image, in bytes (int). Only set if
+	// the [Compression] is a [SizedCompression].
+	MetaBytes = "bytes"
+)
+
+// Metadata is typed key/value metadata of a [Processed] image. Unlike [Tags],
+// which are meant for grouping and finding images, Metadata provides typed
+// values that don't need to be parsed.
+//
+// Processors must not modify the Metadata of their input image in place
+// because it may be shared with other images. Use [Metadata.With] instead.
+type Metadata map[string]any
+
+// With returns a copy of the metadata with the given key set to value.
+func (m Metadata) With(key string, value any) Metadata {
+	out := m.Clone()
+	out[key] = value
+	return out
+}
+
+// Merge returns a copy of the metadata with all values of other added.
+// Values of other take precedence.
+func (m Metadata) Merge(other Metadata) Metadata {
+	out := m.Clone()
+	for k, v := range other {
+		out[k] = v
+	}
+	return out
+}
+
+// Clone returns a copy of the metadata. The returned Metadata is never nil.
+func (m Metadata) Clone() Metadata {
+	out := make(Metadata, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Get returns the value of the given key.
+func (m Metadata) Get(key string) (any, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// String returns the value of the given key if it is a string.
+func (m Metadata) String(key string) (string, bool) {
+	return MetaValue[string](m, key)
+}
+
+// Int returns the value of the given key if it is an int.
+func (m Metadata) Int(key string) (int, bool) {
+	return MetaValue[int](m, key)
+}
+
+// Float returns the value of the given key if it is a float64.
+func (m Metadata) Float(key string) (float64, bool) {
+	return MetaValue[float64](m, key)
+}
+
+// Bool returns the value of the given key if it is a bool.
+func (m Metadata) Bool(key string) (bool, bool) {
+	return MetaValue[bool](m, key)
+}
+
+// Dimensions returns the [Dimensions] that are stored under [MetaWidth] and
+// [MetaHeight].
+func (m Metadata) Dimensions() (Dimensions, bool) {
+	width, ok := m.Int(MetaWidth)
+	if !ok {
+		return Dimensions{}, false
+	}
+
+	height, ok := m.Int(MetaHeight)
+	if !ok {
+		return Dimensions{}, false
+	}
+
+	return Dimensions{width, height}, true
+}
+
+// MetaValue returns the value of the given key if it is of type T.
+func MetaValue[T any](m Metadata, key string) (T, bool) {
+	v, ok := m[key].(T)
+	return v, ok
+}
+
+// encodingKeys are the metadata keys that describe how an image was encoded.
+// They no longer apply once the pixels of the image change.
+var encodingKeys = []string{MetaCompression, MetaQuality, MetaBytes}
+
+// withBounds returns a copy of the metadata with the width and height of the
+// given image. The encoding metadata is dropped, because it describes the
+// encoded input image, not img.
+func (m Metadata) withBounds(img image.Image) Metadata {
+	out := m.Clone()
+	for _, key := range encodingKeys {
+		delete(out, key)
+	}
+	if img != nil {
+		out[MetaWidth] = img.Bounds().Dx()
+		out[MetaHeight] = img.Bounds().Dy()
+	}
+	return out
+}
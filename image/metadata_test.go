@@ -0,0 +1,107 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestMetadata_With(t *testing.T) {
+	meta := image.Metadata{"foo": "bar"}
+
+	with := meta.With("baz", 3)
+
+	if _, ok := meta.Get("baz"); ok {
+		t.Fatalf("With() should not modify the original metadata")
+	}
+
+	if v, ok := with.Int("baz"); !ok || v != 3 {
+		t.Fatalf("Int(%q) should return %d; got %d", "baz", 3, v)
+	}
+
+	if v, ok := with.String("foo"); !ok || v != "bar" {
+		t.Fatalf("String(%q) should return %q; got %q", "foo", "bar", v)
+	}
+
+	if _, ok := with.Int("foo"); ok {
+		t.Fatalf("Int(%q) should fail for a string value", "foo")
+	}
+
+	if v := image.Metadata(nil).With("foo", true); !v["foo"].(bool) {
+		t.Fatalf("With() should work on nil metadata")
+	}
+}
+
+func TestPipeline_Run_Metadata(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}}),
+		image.Compress(compression.JPEG(75)),
+	}
+
+	original := newExample()
+
+	result, err := pipe.Run(context.Background(), original)
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	org, _ := result.Original()
+	dim, ok := org.Meta.Dimensions()
+	if !ok || dim.Width() != original.Bounds().Dx() || dim.Height() != original.Bounds().Dy() {
+		t.Fatalf("original image should have dimensions %v in its metadata; got %v", original.Bounds().Size(), dim)
+	}
+
+	for _, img := range result.Find("resized") {
+		dim, ok := img.Meta.Dimensions()
+		if !ok || dim.Width() != img.Image.Bounds().Dx() || dim.Height() != img.Image.Bounds().Dy() {
+			t.Fatalf("resized image should have dimensions %v in its metadata; got %v", img.Image.Bounds().Size(), dim)
+		}
+
+		if size, _ := img.Meta.String(image.MetaSize); size != image.DimensionName(img.Tags) {
+			t.Fatalf("resized image should have size %q in its metadata; got %q", image.DimensionName(img.Tags), size)
+		}
+
+		if name, _ := img.Meta.String(image.MetaCompression); name != "jpeg" {
+			t.Fatalf("compressed image should have compression %q in its metadata; got %q", "jpeg", name)
+		}
+
+		if quality, _ := img.Meta.Int(image.MetaQuality); quality != 75 {
+			t.Fatalf("compressed image should have quality %d in its metadata; got %d", 75, quality)
+		}
+
+		if bytes, _ := img.Meta.Int(image.MetaBytes); bytes <= 0 {
+			t.Fatalf("compressed image should have its encoded size in its metadata; got %d", bytes)
+		}
+	}
+}
+
+func TestProcessed_Derive_dropsEncodingMetadata(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Compress(compression.JPEG(75), image.CompressOriginal(true)),
+		image.Crop(stdimage.Rect(0, 0, 100, 50)),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	img := result.Images[0]
+
+	for _, key := range []string{image.MetaCompression, image.MetaQuality, image.MetaBytes} {
+		if v, ok := img.Meta.Get(key); ok {
+			t.Fatalf("cropped image should not have %q in its metadata; got %v", key, v)
+		}
+	}
+
+	if dim, ok := img.Meta.Dimensions(); !ok || dim.Width() != 100 || dim.Height() != 50 {
+		t.Fatalf("cropped image should have dimensions 100x50 in its metadata; got %v", dim)
+	}
+}
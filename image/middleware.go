@@ -0,0 +1,149 @@
+package image
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Middleware wraps a [Processor] to add behavior before and after the
+// [Processor] processes an image.
+type Middleware func(Processor) Processor
+
+// WithMiddleware returns a RunOption that wraps every [Processor] of the
+// [Pipeline] with the provided [Middleware]. The first [Middleware] is the
+// outermost one. Pipelines that are run by a [Processor], like the branches of
+// a [*Brancher], inherit the middleware.
+func WithMiddleware(mw ...Middleware) RunOption {
+	return func(r *runner) {
+		r.middleware = append(r.middleware, mw...)
+	}
+}
+
+func (r *runner) wrap(processor Processor) Processor {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		processor = r.middleware[i](processor)
+	}
+	return processor
+}
+
+// Stage is a single step of a [Pipeline].
+type Stage struct {
+	// Index is the position of the [Processor] within the [Pipeline].
+	Index int
+
+	// Processor is the [Processor] of the stage, without any [Middleware].
+	Processor Processor
+}
+
+type stageKey struct{}
+
+// StageOf returns the [Stage] of the [Pipeline] that is currently running.
+// Processors and [Middleware] can call StageOf with their [ProcessorContext].
+// If ctx is not the context of a running [Pipeline], false is returned.
+func StageOf(ctx context.Context) (Stage, bool) {
+	stage, ok := ctx.Value(stageKey{}).(Stage)
+	return stage, ok
+}
+
+// Hooks are functions that are called by a [Pipeline] while it runs. Each of
+// the hooks is optional.
+type Hooks struct {
+	// BeforeStage is called before a [Stage] processes its input images.
+	BeforeStage func(ctx context.Context, stage Stage, inputs []Processed)
+
+	// AfterProcess is called after a [Processor] processed an image.
+	AfterProcess func(ctx context.Context, event ProcessEvent)
+
+	// OnError is called when a [Processor] fails to process an image.
+	OnError func(ctx context.Context, event ProcessEvent)
+}
+
+// ProcessEvent describes a single call to [Processor.Process] within a
+// [Pipeline].
+type ProcessEvent struct {
+	// Stage is the [Stage] that processed the image.
+	Stage Stage
+
+	// Input is the image that was passed to the [Processor].
+	Input Processed
+
+	// Output are the images that were returned by the [Processor].
+	Output []Processed
+
+	// Duration is the time it took to process the image, including the time
+	// spent in [Middleware].
+	Duration time.Duration
+
+	// Err is the error returned by the [Processor], if any.
+	Err error
+}
+
+// WithHooks returns a RunOption that registers [Hooks] for a [Pipeline] run.
+// WithHooks may be passed multiple times; the hooks are called in the order
+// they were registered.
+func WithHooks(hooks Hooks) RunOption {
+	return func(r *runner) {
+		r.hooks = append(r.hooks, hooks)
+	}
+}
+
+func (r *runner) beforeStage(ctx context.Context, stage Stage, inputs []Processed) {
+	for _, hooks := range r.hooks {
+		if hooks.BeforeStage != nil {
+			hooks.BeforeStage(ctx, stage, inputs)
+		}
+	}
+}
+
+func (r *runner) afterProcess(ctx context.Context, event ProcessEvent) {
+	for _, hooks := range r.hooks {
+		if hooks.AfterProcess != nil {
+			hooks.AfterProcess(ctx, event)
+		}
+	}
+}
+
+func (r *runner) onError(ctx context.Context, event ProcessEvent) {
+	for _, hooks := range r.hooks {
+		if hooks.OnError != nil {
+			hooks.OnError(ctx, event)
+		}
+	}
+}
+
+// Logging returns a [Middleware] that logs every processed image to the
+// provided logger. Successfully processed images are logged at debug level,
+// errors are logged at error level. If logger is nil, [slog.Default] is used.
+func Logging(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next Processor) Processor {
+		return ProcessorFunc(func(ctx ProcessorContext) ([]Processed, error) {
+			name := ProcessorName(next)
+			attrs := []slog.Attr{slog.Any("tags", ctx.Image().Tags)}
+
+			if stage, ok := StageOf(ctx); ok {
+				name = ProcessorName(stage.Processor)
+				attrs = append(attrs, slog.Int("stage", stage.Index))
+			}
+
+			attrs = append(attrs, slog.String("processor", name))
+
+			start := time.Now()
+			processed, err := next.Process(ctx)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "process image", append(attrs, slog.Any("error", err))...)
+				return processed, err
+			}
+
+			logger.LogAttrs(ctx, slog.LevelDebug, "process image", append(attrs, slog.Int("outputs", len(processed)))...)
+
+			return processed, nil
+		})
+	}
+}
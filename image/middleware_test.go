@@ -0,0 +1,126 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var calls []string
+	middleware := func(name string) image.Middleware {
+		return func(next image.Processor) image.Processor {
+			return image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+				calls = append(calls, name)
+				return next.Process(ctx)
+			})
+		}
+	}
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionList{{360}, {640}}),
+		image.Tag(image.NewTags("foo")),
+	}
+
+	if _, err := pipe.Run(context.Background(), newExample(), image.WithMiddleware(middleware("a"), middleware("b"))); err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	// 1 call to the Resizer + 3 calls to the Tagger, each through both middlewares.
+	want := "a,b,a,b,a,b,a,b"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("middleware should be called in order %q; got %q", want, got)
+	}
+}
+
+func TestWithHooks(t *testing.T) {
+	var (
+		stages  []int
+		inputs  []int
+		events  []image.ProcessEvent
+		errored []image.ProcessEvent
+	)
+
+	mockError := errors.New("mock error")
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionList{{360}, {640}}),
+		image.Compress(compression.JPEG(80)),
+		image.WhenTagged(image.NewTags(image.Compressed), image.ProcessorFunc(func(image.ProcessorContext) ([]image.Processed, error) {
+			return nil, mockError
+		})),
+	}
+
+	_, err := pipe.Run(context.Background(), newExample(), image.WithHooks(image.Hooks{
+		BeforeStage: func(_ context.Context, stage image.Stage, in []image.Processed) {
+			stages = append(stages, stage.Index)
+			inputs = append(inputs, len(in))
+		},
+		AfterProcess: func(_ context.Context, event image.ProcessEvent) {
+			events = append(events, event)
+		},
+		OnError: func(_ context.Context, event image.ProcessEvent) {
+			errored = append(errored, event)
+		},
+	}))
+
+	if !errors.Is(err, mockError) {
+		t.Fatalf("Run() should fail with %q; got %q", mockError, err)
+	}
+
+	if len(stages) != 3 || stages[0] != 0 || stages[1] != 1 || stages[2] != 2 {
+		t.Fatalf("BeforeStage should be called for stages [0 1 2]; got %v", stages)
+	}
+
+	if len(inputs) != 3 || inputs[0] != 1 || inputs[1] != 3 || inputs[2] != 3 {
+		t.Fatalf("BeforeStage should receive [1 3 3] inputs; got %v", inputs)
+	}
+
+	// 1 resize + 3 compressions + 1 skipped original
+	if len(events) != 5 {
+		t.Fatalf("AfterProcess should be called %d times; was called %d times", 5, len(events))
+	}
+
+	if events[0].Stage.Index != 0 || len(events[0].Output) != 3 {
+		t.Fatalf("first event should be the resize stage with 3 outputs; got stage %d with %d outputs", events[0].Stage.Index, len(events[0].Output))
+	}
+
+	if len(errored) != 1 {
+		t.Fatalf("OnError should be called once; was called %d times", len(errored))
+	}
+
+	if errored[0].Stage.Index != 2 || !errors.Is(errored[0].Err, mockError) {
+		t.Fatalf("OnError should be called for stage 2 with %q; got stage %d with %q", mockError, errored[0].Stage.Index, errored[0].Err)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pipe := image.Pipeline{image.Tag(image.NewTags("foo"))}
+
+	if _, err := pipe.Run(context.Background(), newExample(), image.WithMiddleware(image.Logging(logger))); err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	out := buf.String()
+
+	if !strings.Contains(out, "processor=*image.Tagger") {
+		t.Fatalf("log should contain the processor type\n%s", out)
+	}
+
+	if !strings.Contains(out, "stage=0") {
+		t.Fatalf("log should contain the stage\n%s", out)
+	}
+
+	if !strings.Contains(out, "duration=") {
+		t.Fatalf("log should contain the duration\n%s", out)
+	}
+}
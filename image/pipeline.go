@@ -2,7 +2,7 @@ package image
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"image"
 	"regexp"
 
@@ -21,7 +21,19 @@ type Pipeline []Processor
 type Processed struct {
 	Image    image.Image
 	Tags     Tags
+	Meta     Metadata
 	Original bool
+
+	// ID identifies the image within a [Pipeline] run. IDs are assigned by
+	// the [Pipeline] to images that a [Processor] returns without an ID.
+	ID ImageID
+
+	// Parent is the ID of the image that this image was derived from.
+	Parent ImageID
+
+	// Chain are the names of the Processors that produced this image from
+	// the original image, as returned by [ProcessorName].
+	Chain []string
 }
 
 // A Processor processes an image and returns possibly multiple processed images.
@@ -32,6 +44,11 @@ type Processor interface {
 // ProcessorFunc allows functions to be used a Processors.
 type ProcessorFunc func(ProcessorContext) ([]Processed, error)
 
+// Process implements [Processor].
+func (fn ProcessorFunc) Process(ctx ProcessorContext) ([]Processed, error) {
+	return fn(ctx)
+}
+
 // ProcessorContext is passed to Processors.
 type ProcessorContext interface {
 	context.Context
@@ -65,85 +82,53 @@ type PipelineResult struct {
 
 	// Input is the original image that was passed to the [Pipeline].
 	Input image.Image
-}
 
-// Tags is a list of tags that Processors assigned to images in a [Pipeline].
-type Tags []string
-
-// NewTags returns the given tags as [Tags]. Duplicates are removed.
-func NewTags(tags ...string) Tags {
-	return slices.Unique(Tags(tags))
-}
-
-// Match returns the tags that match the given regular expression.
-func (tags Tags) Match(re *regexp.Regexp) []string {
-	var out []string
-	for _, tag := range tags {
-		if re.MatchString(tag) {
-			out = append(out, tag)
-		}
-	}
-	return out
-}
-
-// Contains returns whether a tag is contained within tags.
-func (tags Tags) Contains(tag string) bool {
-	return slices.Contains(tag, tags)
-}
+	// Errors are the errors of images that were skipped because of the
+	// [ErrorPolicy] of the [Pipeline]. Errors is always empty when using the
+	// default [FailFast] policy.
+	Errors []*ProcessorError
 
-// With appends additional tags and returns the new [Tags]. Duplicate tags are removed.
-func (tags Tags) With(add ...string) Tags {
-	return slices.Unique(append(tags, add...))
-}
-
-// Without returns a copy of tags without the given tags.
-func (tags Tags) Without(remove ...string) Tags {
-	out := make(Tags, 0, len(tags))
-	for _, tag := range tags {
-		if slices.Contains(tag, remove) {
-			continue
-		}
-		out = append(out, tag)
-	}
-	return out
+	// Lineage is the [Provenance] of all images that were produced by the
+	// [Pipeline], including intermediate images that are not part of Images.
+	Lineage map[ImageID]Provenance
 }
 
 // Run runs the pipeline on an image and returns the [PipelineResult],
 // containing the processed images.
-func (pipeline Pipeline) Run(ctx context.Context, img image.Image) (PipelineResult, error) {
-	previous := []Processed{{Image: img, Tags: NewTags(Original), Original: true}}
+func (pipeline Pipeline) Run(ctx context.Context, img image.Image, opts ...RunOption) (PipelineResult, error) {
+	r := newRunner(opts...)
 
-	for _, processor := range pipeline {
-		_previous := previous
-		previous = previous[:0]
+	var errs []*ProcessorError
+	r.skipped = func(err *ProcessorError) {
+		errs = append(errs, err)
+	}
 
-		for _, img := range _previous {
-			pctx := NewProcessorContext(ctx, img)
+	processed, err := r.run(ctx, pipeline, newOriginal(img))
+	if err != nil {
+		return PipelineResult{}, err
+	}
 
-			processed, err := processor.Process(pctx)
-			if err != nil {
-				return PipelineResult{}, fmt.Errorf("%T processor: %w", processor, err)
-			}
+	result := PipelineResult{
+		Images:  processed,
+		Input:   img,
+		Errors:  errs,
+		Lineage: r.lineage.nodes,
+	}
 
-			var originalCount int
-			for _, pimg := range processed {
-				if pimg.Original {
-					originalCount++
-				}
+	if r.errorPolicy == CollectErrors && len(errs) > 0 {
+		return result, errors.Join(slices.Map(func(err *ProcessorError) error { return err }, errs)...)
+	}
 
-				if originalCount > 1 {
-					return PipelineResult{}, fmt.Errorf("%T processor returned more than one %q image", processor, Original)
-				}
-			}
+	return result, nil
+}
 
-			previous = append(previous, processed...)
-		}
+func newOriginal(img image.Image) Processed {
+	return Processed{
+		Image:    img,
+		Tags:     NewTags(Original),
+		Meta:     Metadata(nil).withBounds(img),
+		Original: true,
 	}
-
-	return PipelineResult{
-		Images: previous,
-		Input:  img,
-	}, nil
 }
 
 // Original returns the processed image that is tagged as the original image.
@@ -2,7 +2,9 @@ package image_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -209,3 +211,33 @@ func TestPipeline_Run_ResizeDiscardInput(t *testing.T) {
 		t.Fatalf("first image should have tag %q", "compressed")
 	}
 }
+
+func TestPipeline_Run_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}, "lg": {960}}),
+		image.ProcessorFunc(func(pctx image.ProcessorContext) ([]image.Processed, error) {
+			calls++
+			cancel()
+			return []image.Processed{pctx.Image()}, nil
+		}),
+	}
+
+	_, err := pipe.Run(ctx, newExample(), image.WithErrorPolicy(image.SkipFailed))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() should fail with %q; got %q", context.Canceled, err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("processor should not be called after the context is canceled; was called %d times", calls)
+	}
+
+	// Stages are numbered from 0, like in ProcessorError.
+	want := "stage 1: processed 1 of 4 images"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error should contain %q; got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,124 @@
+package image
+
+import "image"
+
+// ProgressEvent is the type of a [Progress] report.
+type ProgressEvent int
+
+const (
+	// StageStarted is reported before a [Stage] processes its input images.
+	StageStarted ProgressEvent = iota
+
+	// ImageProcessed is reported after a [Stage] processed one of its input
+	// images.
+	ImageProcessed
+
+	// StageFinished is reported after a [Stage] processed all of its input
+	// images.
+	StageFinished
+)
+
+func (e ProgressEvent) String() string {
+	switch e {
+	case StageStarted:
+		return "stage_started"
+	case ImageProcessed:
+		return "image_processed"
+	case StageFinished:
+		return "stage_finished"
+	default:
+		return "unknown"
+	}
+}
+
+// Progress is a progress report of a running [Pipeline].
+type Progress struct {
+	// Event is the type of the report.
+	Event ProgressEvent
+
+	// Stage is the index of the current stage.
+	Stage int
+
+	// Stages is the number of stages of the [Pipeline].
+	Stages int
+
+	// Image is the number of input images that the current stage has processed
+	// so far.
+	Image int
+
+	// Images is the number of input images of the current stage.
+	Images int
+
+	// Bytes is the in-memory size of the images that were returned by the
+	// Processor for the last processed image. Bytes is only set for
+	// [ImageProcessed] reports.
+	Bytes int64
+
+	// TotalBytes is the in-memory size of all images that were returned by
+	// the current stage so far.
+	TotalBytes int64
+}
+
+// Done returns the fraction of the [Pipeline] that has been completed, in the
+// range [0, 1]. Each stage makes up an equal share of the [Pipeline].
+func (p Progress) Done() float64 {
+	if p.Stages == 0 {
+		return 1
+	}
+
+	var stage float64
+	if p.Images > 0 {
+		stage = float64(p.Image) / float64(p.Images)
+	}
+
+	return (float64(p.Stage) + stage) / float64(p.Stages)
+}
+
+// WithProgress returns a RunOption that reports the progress of a [Pipeline]
+// to the provided function. The function is called synchronously, so it
+// should return quickly, for example by sending the report to a buffered
+// channel.
+func WithProgress(fn func(Progress)) RunOption {
+	return func(r *runner) {
+		r.progress = append(r.progress, fn)
+	}
+}
+
+func (r *runner) report(p Progress) {
+	for _, fn := range r.progress {
+		fn(p)
+	}
+}
+
+// bytesOf returns the in-memory size of img in bytes.
+func bytesOf(img image.Image) int64 {
+	switch img := img.(type) {
+	case *image.NRGBA:
+		return int64(len(img.Pix))
+	case *image.RGBA:
+		return int64(len(img.Pix))
+	case *image.NRGBA64:
+		return int64(len(img.Pix))
+	case *image.RGBA64:
+		return int64(len(img.Pix))
+	case *image.Gray:
+		return int64(len(img.Pix))
+	case *image.Gray16:
+		return int64(len(img.Pix))
+	case *image.Alpha:
+		return int64(len(img.Pix))
+	case *image.Paletted:
+		return int64(len(img.Pix))
+	case *image.CMYK:
+		return int64(len(img.Pix))
+	case *image.YCbCr:
+		return int64(len(img.Y) + len(img.Cb) + len(img.Cr))
+	case *image.NYCbCrA:
+		return int64(len(img.Y) + len(img.Cb) + len(img.Cr) + len(img.A))
+	case nil:
+		return 0
+	default:
+		b := img.Bounds()
+		return int64(b.Dx()) * int64(b.Dy()) * 4
+	}
+}
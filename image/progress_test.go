@@ -0,0 +1,63 @@
+package image_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestWithProgress(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionList{{360}, {640}}),
+		image.Compress(compression.JPEG(80)),
+	}
+
+	var reports []image.Progress
+	if _, err := pipe.Run(context.Background(), newExample(), image.WithProgress(func(p image.Progress) {
+		reports = append(reports, p)
+	})); err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	want := []struct {
+		event  image.ProgressEvent
+		stage  int
+		image  int
+		images int
+	}{
+		{image.StageStarted, 0, 0, 1},
+		{image.ImageProcessed, 0, 1, 1},
+		{image.StageFinished, 0, 1, 1},
+		{image.StageStarted, 1, 0, 3},
+		{image.ImageProcessed, 1, 1, 3},
+		{image.ImageProcessed, 1, 2, 3},
+		{image.ImageProcessed, 1, 3, 3},
+		{image.StageFinished, 1, 3, 3},
+	}
+
+	if len(reports) != len(want) {
+		t.Fatalf("expected %d progress reports; got %d", len(want), len(reports))
+	}
+
+	for i, w := range want {
+		r := reports[i]
+
+		if r.Event != w.event || r.Stage != w.stage || r.Image != w.image || r.Images != w.images {
+			t.Fatalf("report #%d should be %s (stage=%d, image=%d/%d); got %s (stage=%d, image=%d/%d)", i, w.event, w.stage, w.image, w.images, r.Event, r.Stage, r.Image, r.Images)
+		}
+
+		if r.Stages != 2 {
+			t.Fatalf("report #%d should report %d stages; got %d", i, 2, r.Stages)
+		}
+
+		if r.Event == image.ImageProcessed && r.Bytes <= 0 {
+			t.Fatalf("report #%d should report the produced bytes", i)
+		}
+	}
+
+	if last := reports[len(reports)-1]; last.Done() != 1 {
+		t.Fatalf("last report should be done; got %v", last.Done())
+	}
+}
@@ -0,0 +1,429 @@
+package image
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed tag query. Queries select images by their [Tags] using
+// boolean expressions, for example:
+//
+//	resized AND compression=jpeg AND NOT size=xs
+//	quality>=70 OR original
+//	(size=sm OR size=md) && !compressed
+//
+// A bare word matches images that have the word as a tag, or that have a
+// structured tag with the word as its key (see [ParseTag]). A comparison
+// "key<op>value" matches images that have a structured tag with an attribute
+// with the given key whose value satisfies the comparison. Supported
+// operators are =, !=, <, <=, > and >=. != also matches images that have no
+// attribute with the given key. The ordering operators compare numerically
+// and never match non-numeric values.
+//
+// Expressions can be combined with AND (&&), OR (||) and NOT (!), and grouped
+// with parentheses. AND binds stronger than OR. Keywords are case-insensitive.
+// Words and values that contain whitespace or special characters can be
+// quoted with double quotes.
+type Query struct {
+	expr string
+	root queryNode
+}
+
+// QueryError is returned by [ParseQuery] for invalid queries.
+type QueryError struct {
+	// Query is the query that failed to parse.
+	Query string
+
+	// Pos is the byte offset within Query at which the error occurred.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("parse query %q: %s at position %d", err.Query, err.Msg, err.Pos)
+}
+
+// ParseQuery parses a tag query. If the query is invalid, a [*QueryError] is
+// returned.
+func ParseQuery(expr string) (*Query, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := queryParser{expr: expr, tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+
+	return &Query{expr: expr, root: root}, nil
+}
+
+// MustParseQuery parses a tag query and panics if the query is invalid.
+func MustParseQuery(expr string) *Query {
+	q, err := ParseQuery(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Match returns whether the given tags match the query.
+func (q *Query) Match(tags Tags) bool {
+	return q.root.match(tags)
+}
+
+// String returns the query expression.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Query returns the processed images whose tags match the given query
+// expression. See [Query] for the syntax of the expression.
+func (result PipelineResult) Query(expr string) ([]Processed, error) {
+	q, err := ParseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	return result.Select(q), nil
+}
+
+// Select returns the processed images whose tags match the given [*Query].
+func (result PipelineResult) Select(q *Query) []Processed {
+	var out []Processed
+	for _, img := range result.Images {
+		if q.Match(img.Tags) {
+			out = append(out, img)
+		}
+	}
+	return out
+}
+
+type queryNode interface {
+	match(Tags) bool
+}
+
+type andNode struct{ left, right queryNode }
+
+func (n andNode) match(tags Tags) bool { return n.left.match(tags) && n.right.match(tags) }
+
+type orNode struct{ left, right queryNode }
+
+func (n orNode) match(tags Tags) bool { return n.left.match(tags) || n.right.match(tags) }
+
+type notNode struct{ node queryNode }
+
+func (n notNode) match(tags Tags) bool { return !n.node.match(tags) }
+
+type hasNode struct{ name string }
+
+func (n hasNode) match(tags Tags) bool {
+	if tags.Contains(n.name) {
+		return true
+	}
+	_, ok := tags.Get(n.name)
+	return ok
+}
+
+type compareNode struct {
+	key   string
+	op    string
+	value string
+}
+
+func (n compareNode) match(tags Tags) bool {
+	values := attributeValues(tags, n.key)
+
+	if n.op == "!=" {
+		for _, v := range values {
+			if v == n.value {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, v := range values {
+		if n.compare(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n compareNode) compare(v string) bool {
+	if n.op == "=" {
+		return v == n.value
+	}
+
+	a, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false
+	}
+
+	b, err := strconv.ParseFloat(n.value, 64)
+	if err != nil {
+		return false
+	}
+
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	default:
+		return false
+	}
+}
+
+// attributeValues returns the values of all attributes with the given key of
+// all structured tags.
+func attributeValues(tags Tags, key string) []string {
+	var out []string
+	for _, tag := range tags {
+		attrs, ok := ParseTag(tag)
+		if !ok {
+			continue
+		}
+		for _, attr := range attrs {
+			if attr.Key == key {
+				out = append(out, attr.Value)
+			}
+		}
+	}
+	return out
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+func lexQuery(expr string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, value: "&&", pos: i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{kind: tokenOr, value: "||", pos: i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "!="), strings.HasPrefix(expr[i:], "<="), strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{kind: tokenOp, value: expr[i : i+2], pos: i})
+			i += 2
+		case c == '=' || c == '<' || c == '>':
+			tokens = append(tokens, token{kind: tokenOp, value: expr[i : i+1], pos: i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{kind: tokenNot, value: "!", pos: i})
+			i++
+		case c == '&' || c == '|':
+			return nil, &QueryError{Query: expr, Pos: i, Msg: fmt.Sprintf("unexpected %q", c)}
+		case c == '"':
+			value, n, err := lexQuoted(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: value, pos: i})
+			i += n
+		default:
+			start := i
+			for i < len(expr) && isWordChar(rune(expr[i])) {
+				i++
+			}
+			if i == start {
+				return nil, &QueryError{Query: expr, Pos: i, Msg: fmt.Sprintf("unexpected %q", c)}
+			}
+
+			word := expr[start:i]
+			tok := token{kind: tokenWord, value: word, pos: start}
+			switch strings.ToUpper(word) {
+			case "AND":
+				tok.kind = tokenAnd
+			case "OR":
+				tok.kind = tokenOr
+			case "NOT":
+				tok.kind = tokenNot
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func isWordChar(r rune) bool {
+	if r > unicode.MaxASCII {
+		return true
+	}
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`()!=<>&|"`, r)
+}
+
+// lexQuoted lexes the quoted string that starts at expr[start] and returns
+// the unquoted value and the length of the quoted string.
+func lexQuoted(expr string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if i+1 >= len(expr) {
+				return "", 0, &QueryError{Query: expr, Pos: i, Msg: "unterminated escape sequence"}
+			}
+			i++
+			b.WriteByte(expr[i])
+		case '"':
+			return b.String(), i - start + 1, nil
+		default:
+			b.WriteByte(expr[i])
+		}
+	}
+	return "", 0, &QueryError{Query: expr, Pos: start, Msg: "unterminated string"}
+}
+
+type queryParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) errorf(tok token, format string, args ...any) error {
+	return &QueryError{Query: p.expr, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "expected %q, got %s", ")", closing)
+		}
+		return node, nil
+	case tokenWord:
+		if p.peek().kind != tokenOp {
+			return hasNode{name: tok.value}, nil
+		}
+
+		op := p.next()
+		value := p.next()
+		if value.kind != tokenWord {
+			return nil, p.errorf(value, "expected value after %q, got %s", op.value, value)
+		}
+
+		return compareNode{key: tok.value, op: op.value, value: value.value}, nil
+	default:
+		return nil, p.errorf(tok, "expected tag or %q, got %s", "(", tok)
+	}
+}
@@ -0,0 +1,122 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+)
+
+func TestQuery_Match(t *testing.T) {
+	tags := image.NewTags("resized", "size=md", "compressed", "compression=jpeg,quality=75", "my tag")
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"resized", true},
+		{"original", false},
+		{"size", true},
+		{"size=md", true},
+		{"size=xs", false},
+		{"size!=xs", true},
+		{"size!=md", false},
+		{"resized AND compression=jpeg AND NOT size=xs", true},
+		{"resized and compression=png", false},
+		{"original OR size=md", true},
+		{"quality>=70", true},
+		{"quality>75", false},
+		{"quality<=75 && quality>74", true},
+		{"quality<50 || !compressed", false},
+		{"(size=sm OR size=md) AND compressed", true},
+		{"NOT (size=sm OR size=md)", false},
+		{"size=xs OR size=sm AND compressed", false},
+		{"size=md OR size=sm AND original", true},
+		{`"my tag"`, true},
+		{`size="md"`, true},
+		{"quality>=abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := image.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse query: %v", err)
+			}
+
+			if got := q.Match(tags); got != tt.want {
+				t.Fatalf("Match() should return %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseQuery_error(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"", 0},
+		{"resized AND", 11},
+		{"(resized", 8},
+		{"resized)", 7},
+		{"size=", 5},
+		{"size = AND", 7},
+		{"resized & compressed", 8},
+		{`"unterminated`, 0},
+		{"resized compressed", 8},
+		{"NOT", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := image.ParseQuery(tt.query)
+
+			var qerr *image.QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("ParseQuery() should return a %T; got %T (%v)", qerr, err, err)
+			}
+
+			if qerr.Pos != tt.pos {
+				t.Fatalf("error should be at position %d; got %d (%v)", tt.pos, qerr.Pos, qerr)
+			}
+		})
+	}
+}
+
+func TestPipelineResult_Query(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"xs": {120}, "sm": {360}, "md": {640}}),
+		image.CompressMany([]image.Compression{compression.JPEG(80), compression.JPEG(50)}),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	images, err := result.Query("resized AND compression=jpeg AND NOT size=xs AND quality>=70")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if len(images) != 2 {
+		t.Fatalf("query should return 2 images; got %d", len(images))
+	}
+
+	for _, img := range images {
+		if name := image.DimensionName(img.Tags); name != "sm" && name != "md" {
+			t.Fatalf("query should only return %q and %q images; got %q", "sm", "md", name)
+		}
+
+		if q := image.CompressionQuality(img.Tags); q != 80 {
+			t.Fatalf("query should only return images with quality %d; got %d", 80, q)
+		}
+	}
+
+	if _, err := result.Query("resized AND"); err == nil {
+		t.Fatalf("Query() should fail for invalid queries")
+	}
+}
@@ -0,0 +1,109 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/modernice/media-tools/image"
+)
+
+func newCheckerboard(size int) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x+y)%2 == 0 {
+				img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{0, 0, 0, 255})
+			}
+		}
+	}
+	return img
+}
+
+func resizeOne(t *testing.T, img stdimage.Image, dim image.Dimensions, opts ...image.ResizerOption) *stdimage.NRGBA {
+	t.Helper()
+
+	resized, err := image.Resize(image.DimensionList{dim}, opts...).Resize(img)
+	if err != nil {
+		t.Fatalf("resize image: %v", err)
+	}
+
+	if len(resized) != 1 {
+		t.Fatalf("expected 1 resized image; got %d", len(resized))
+	}
+
+	return imaging.Clone(resized[0])
+}
+
+func TestLinearLight_checkerboard(t *testing.T) {
+	img := newCheckerboard(64)
+
+	// Half of the light of a black-and-white checkerboard is 50% linear light,
+	// which is ~188 in sRGB.
+	linear := resizeOne(t, img, image.Dimensions{16, 16}, image.LinearLight(true), image.ResampleFilter(imaging.Box))
+	for _, px := range [][2]int{{0, 0}, {7, 7}, {15, 15}} {
+		if c := linear.NRGBAAt(px[0], px[1]); c.R < 185 || c.R > 190 {
+			t.Fatalf("linear-light resampling should produce a gray of ~188 at %v; got %v", px, c)
+		}
+	}
+
+	srgb := resizeOne(t, img, image.Dimensions{16, 16}, image.ResampleFilter(imaging.Box))
+	if c := srgb.NRGBAAt(7, 7); c.R > 130 {
+		t.Fatalf("sRGB resampling should produce a gray of ~128; got %v", c)
+	}
+}
+
+func TestLinearLight_alphaEdge(t *testing.T) {
+	// Left half is opaque red, right half is fully transparent black.
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+
+	resized := resizeOne(t, img, image.Dimensions{20, 20}, image.LinearLight(true))
+
+	var semi int
+	for x := 0; x < 20; x++ {
+		c := resized.NRGBAAt(x, 10)
+		if c.A == 0 {
+			continue
+		}
+		if c.A < 255 {
+			semi++
+		}
+		if c.R < 250 || c.G > 5 || c.B > 5 {
+			t.Fatalf("edge pixel %d should keep the color of the opaque pixels; got %v", x, c)
+		}
+	}
+
+	if semi == 0 {
+		t.Fatalf("resized image should have semi-transparent edge pixels")
+	}
+}
+
+func TestLinearLight_Pipeline(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {50, 0}}, image.LinearLight(true), image.DiscardInput(true)),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	img, err := result.Single("size=sm")
+	if err != nil {
+		t.Fatalf("find resized image: %v", err)
+	}
+
+	expected := imaging.Resize(newExample(), 50, 0, imaging.Lanczos)
+	if img.Image.Bounds() != expected.Bounds() {
+		t.Fatalf("resized image should have bounds %v; got %v", expected.Bounds(), img.Image.Bounds())
+	}
+}
@@ -1,19 +1,30 @@
 package image
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"image"
-	"strings"
+	"math"
+	"runtime"
+	stdslices "slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/disintegration/imaging"
 	"github.com/modernice/media-tools/internal/slices"
-	stdslices "golang.org/x/exp/slices"
 )
 
 var _ Processor = (*Resizer)(nil)
 
-// Resized is the tag that is assigned to resized images.
-const Resized = "resized"
+const (
+	// Resized is the tag that is assigned to resized images.
+	Resized = "resized"
+
+	// SizeKey is the key of the structured tag that provides the name of the
+	// [Dimensions] of a resized image, e.g. "size=sm".
+	SizeKey = "size"
+)
 
 // Resizer resizes images to a set of dimensions.
 type Resizer struct {
@@ -21,10 +32,17 @@ type Resizer struct {
 	dimensions        DimensionList
 	filter            imaging.ResampleFilter
 	discardInput      bool
+	densities         []float64
+	linear            bool
+	concurrency       int
+	progressive       float64
+	filters           map[string]imaging.ResampleFilter
+	sharpen           map[string]UnsharpMask
 }
 
 // DimensionProvider provides image dimensions to Resizer. DimensionProvider is
-// implemented by [DimensionList] and [DimensionMap].
+// implemented by [DimensionList] and [DimensionMap]. To provide dimensions that
+// depend on the input image, implement [DimensionResolver].
 type DimensionProvider interface {
 	Dimensions() []Dimensions
 }
@@ -40,6 +58,32 @@ func ResampleFilter(filter imaging.ResampleFilter) ResizerOption {
 	}
 }
 
+// DimensionFilter returns a ResizerOption that sets the
+// [imaging.ResampleFilter] for the dimensions with the given name, e.g. a
+// sharper filter for thumbnails. Other dimensions use the filter that is set by
+// [ResampleFilter]. Dimensions are named by [DimensionMap]s and other
+// providers that name their dimensions.
+func DimensionFilter(name string, filter imaging.ResampleFilter) ResizerOption {
+	return func(r *Resizer) {
+		if r.filters == nil {
+			r.filters = make(map[string]imaging.ResampleFilter)
+		}
+		r.filters[name] = filter
+	}
+}
+
+// DimensionSharpen returns a ResizerOption that sharpens the resized images of
+// the dimensions with the given name using the given [UnsharpMask]. Sharpened
+// images are tagged with [Sharpened].
+func DimensionSharpen(name string, mask UnsharpMask) ResizerOption {
+	return func(r *Resizer) {
+		if r.sharpen == nil {
+			r.sharpen = make(map[string]UnsharpMask)
+		}
+		r.sharpen[name] = mask
+	}
+}
+
 // DiscardInput returns a ResizerOption that discards the input image from the
 // resize result when executed in a [Pipeline].
 func DiscardInput(v bool) ResizerOption {
@@ -48,37 +92,46 @@ func DiscardInput(v bool) ResizerOption {
 	}
 }
 
+// Concurrency returns a ResizerOption that resizes up to n dimensions of an
+// image concurrently. If n is 0 or negative, up to [runtime.GOMAXPROCS]
+// dimensions are resized concurrently. Defaults to 1.
+func Concurrency(n int) ResizerOption {
+	return func(r *Resizer) {
+		r.concurrency = n
+	}
+}
+
+// Progressive returns a ResizerOption that derives smaller dimensions from
+// already resized, larger dimensions instead of resizing every dimension from
+// the full-size input image, which is much faster for large input images.
+//
+// To guard the quality of the resized images, a dimension is only derived
+// from a resized image that is at least factor times as large in both
+// directions; otherwise it is resized from the input image. A factor of 2 or
+// more is recommended. Factors below 1 disable progressive resizing, which is
+// the default.
+func Progressive(factor float64) ResizerOption {
+	return func(r *Resizer) {
+		r.progressive = factor
+	}
+}
+
 // Resize returns a Resizer that resizes images to the given dimensions.
+// Duplicate dimensions are only resized once. If a [DimensionMap] provides
+// multiple names for the same [Dimensions], the resized image is tagged with
+// each of the names, e.g. "size=sm" and "size=thumb".
 func Resize(dimensions DimensionProvider, opts ...ResizerOption) *Resizer {
 	r := &Resizer{
 		dimensionProvider: dimensions,
 		filter:            imaging.Lanczos,
+		concurrency:       1,
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
-	r.dimensions = r.dimensionProvider.Dimensions()
-	stdslices.SortFunc(r.dimensions, func(a, b Dimensions) int {
-		if a.Width() < b.Width() {
-			return -1
-		}
-
-		if a.Width() > b.Width() {
-			return 1
-		}
-
-		if a.Height() < b.Height() {
-			return -1
-		}
-
-		if a.Height() > b.Height() {
-			return 1
-		}
-
-		return 0
-	})
+	r.dimensions = sortDimensions(append(DimensionList(nil), r.dimensionProvider.Dimensions()...))
 
 	return r
 }
@@ -86,7 +139,15 @@ func Resize(dimensions DimensionProvider, opts ...ResizerOption) *Resizer {
 // Resize resizes an image to the configured dimensinos. The input image is not
 // returned in the result.
 func (r *Resizer) Resize(img image.Image) ([]image.Image, error) {
-	resized, err := r.resizeInternal(img)
+	provider, dims, err := r.resolve(img)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := r.variants(provider, img.Bounds(), dims)
+	targets := sortDimensions(slices.Map(func(v resizeVariant) Dimensions { return v.target }, variants))
+
+	resized, err := r.resizeInternal(context.Background(), img, targets, r.settings(variants))
 	if err != nil {
 		return nil, err
 	}
@@ -98,19 +159,245 @@ type resizedImage struct {
 	dimensions Dimensions
 }
 
-func (r *Resizer) resizeInternal(img image.Image) ([]resizedImage, error) {
-	resized := make([]resizedImage, len(r.dimensions))
-	for i, dim := range r.dimensions {
+// resolve returns the DimensionProvider and the sorted dimensions for the given
+// input image. If the configured DimensionProvider is a DimensionResolver, the
+// dimensions are resolved for img.
+func (r *Resizer) resolve(img image.Image) (DimensionProvider, []Dimensions, error) {
+	resolver, ok := r.dimensionProvider.(DimensionResolver)
+	if !ok {
+		return r.dimensionProvider, r.dimensions, nil
+	}
+
+	provider, err := resolver.ResolveDimensions(img)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve dimensions: %w", err)
+	}
+
+	return provider, sortDimensions(append(DimensionList(nil), provider.Dimensions()...)), nil
+}
+
+func (r *Resizer) resizeInternal(ctx context.Context, img image.Image, dims []Dimensions, settings map[Dimensions]resizeSettings) ([]resizedImage, error) {
+	tasks := r.plan(img.Bounds(), dims, settings)
+	images := make([]image.Image, len(tasks))
+
+	if r.concurrency == 1 {
+		for n, i := range resizeOrder(tasks) {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("resized %d of %d dimensions: %w", n, len(dims), err)
+			}
+			images[i] = r.resize(tasks[i].source(img, images), tasks[i])
+		}
+	} else if err := r.resizeConcurrent(ctx, img, tasks, images); err != nil {
+		return nil, err
+	}
+
+	resized := make([]resizedImage, len(dims))
+	for i, dim := range dims {
 		resized[i] = resizedImage{
-			image:      r.resize(img, dim),
+			image:      images[i],
 			dimensions: dim,
 		}
 	}
 	return resized, nil
 }
 
-func (r *Resizer) resize(img image.Image, dim Dimensions) image.Image {
-	return imaging.Resize(img, dim.Width(), dim.Height(), r.filter)
+func (r *Resizer) resizeConcurrent(ctx context.Context, img image.Image, tasks []resizeTask, images []image.Image) error {
+	concurrency := r.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	done := make([]chan struct{}, len(tasks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var (
+		wg        sync.WaitGroup
+		completed atomic.Int64
+	)
+
+	// Tasks are started in resize order, so that the sources of progressive
+	// tasks are resized first. A task waits for its source before it acquires
+	// the semaphore, which cannot deadlock.
+	for _, i := range resizeOrder(tasks) {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			task := tasks[i]
+			if task.from >= 0 {
+				<-done[task.from]
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			src := task.source(img, images)
+			if src == nil || ctx.Err() != nil {
+				return
+			}
+
+			images[i] = r.resize(src, task)
+			completed.Add(1)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if n := int(completed.Load()); n < len(tasks) {
+		return fmt.Errorf("resized %d of %d dimensions: %w", n, len(tasks), ctx.Err())
+	}
+
+	return nil
+}
+
+func (r *Resizer) resize(img image.Image, task resizeTask) image.Image {
+	var resized image.Image
+	if r.linear {
+		resized = resizeLinear(img, task.size.Width(), task.size.Height(), task.filter)
+	} else {
+		resized = imaging.Resize(img, task.size.Width(), task.size.Height(), task.filter)
+	}
+
+	if task.sharpen != nil {
+		resized = task.sharpen.Apply(resized)
+	}
+
+	return resized
+}
+
+// resizeSettings are the filter and sharpening of a single dimension.
+type resizeSettings struct {
+	filter  *imaging.ResampleFilter
+	sharpen *UnsharpMask
+}
+
+// settings returns the resize settings of the variants' target dimensions
+// that differ from the defaults of the Resizer. If multiple names of a
+// dimension are configured, the first name wins.
+func (r *Resizer) settings(variants []resizeVariant) map[Dimensions]resizeSettings {
+	if len(r.filters) == 0 && len(r.sharpen) == 0 {
+		return nil
+	}
+
+	out := make(map[Dimensions]resizeSettings)
+	for _, v := range variants {
+		settings := out[v.target]
+		for _, name := range v.names {
+			if filter, ok := r.filters[name]; ok && settings.filter == nil {
+				settings.filter = &filter
+			}
+			if mask, ok := r.sharpen[name]; ok && settings.sharpen == nil {
+				settings.sharpen = &mask
+			}
+		}
+		out[v.target] = settings
+	}
+	return out
+}
+
+// resizeTask resizes an image to a single dimension.
+type resizeTask struct {
+	// size is the size of the resized image. Zero widths and heights are
+	// resolved for the input image.
+	size Dimensions
+
+	// from is the index of the task whose image is resized, or -1 if the
+	// input image is resized.
+	from int
+
+	filter  imaging.ResampleFilter
+	sharpen *UnsharpMask
+}
+
+func (task resizeTask) source(input image.Image, images []image.Image) image.Image {
+	if task.from < 0 {
+		return input
+	}
+	return images[task.from]
+}
+
+func (task resizeTask) area() int {
+	return task.size.Width() * task.size.Height()
+}
+
+// plan returns the resize tasks for an input image with the given bounds. If
+// the Resizer is progressive, each task resizes the smallest resized image
+// that satisfies the quality guard.
+func (r *Resizer) plan(bounds image.Rectangle, dims []Dimensions, settings map[Dimensions]resizeSettings) []resizeTask {
+	tasks := make([]resizeTask, len(dims))
+	for i, dim := range dims {
+		tasks[i] = resizeTask{size: resolveSize(bounds, dim), from: -1, filter: r.filter}
+		if s, ok := settings[dim]; ok {
+			if s.filter != nil {
+				tasks[i].filter = *s.filter
+			}
+			tasks[i].sharpen = s.sharpen
+		}
+	}
+
+	if r.progressive < 1 {
+		return tasks
+	}
+
+	order := resizeOrder(tasks)
+	for n, i := range order {
+		target := tasks[i].size
+		for _, j := range order[:n] {
+			// Sharpening artifacts would be amplified by further resizing.
+			if tasks[j].sharpen != nil {
+				continue
+			}
+
+			src := tasks[j].size
+			if float64(src.Width()) < r.progressive*float64(target.Width()) ||
+				float64(src.Height()) < r.progressive*float64(target.Height()) {
+				continue
+			}
+			if tasks[i].from < 0 || tasks[j].area() < tasks[tasks[i].from].area() {
+				tasks[i].from = j
+			}
+		}
+	}
+
+	return tasks
+}
+
+// resizeOrder returns the indices of the tasks, largest first.
+func resizeOrder(tasks []resizeTask) []int {
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	stdslices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(tasks[b].area(), tasks[a].area())
+	})
+	return order
+}
+
+// resolveSize returns the size of an image with the given bounds after
+// resizing it to dim. Like [imaging.Resize], a zero width or height preserves
+// the aspect ratio.
+func resolveSize(bounds image.Rectangle, dim Dimensions) Dimensions {
+	width, height := dim.Width(), dim.Height()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	if (width == 0) == (height == 0) || srcWidth <= 0 || srcHeight <= 0 {
+		return dim
+	}
+
+	if width == 0 {
+		width = int(math.Max(1, math.Floor(float64(height)*float64(srcWidth)/float64(srcHeight)+0.5)))
+	}
+
+	if height == 0 {
+		height = int(math.Max(1, math.Floor(float64(width)*float64(srcHeight)/float64(srcWidth)+0.5)))
+	}
+
+	return Dimensions{width, height}
 }
 
 // Process implements [Processor]. The input image is returned in the result as
@@ -118,25 +405,53 @@ func (r *Resizer) resize(img image.Image, dim Dimensions) image.Image {
 func (r *Resizer) Process(ctx ProcessorContext) ([]Processed, error) {
 	input := ctx.Image()
 
-	resized, err := r.resizeInternal(input.Image)
+	provider, dims, err := r.resolve(input.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := r.variants(provider, input.Image.Bounds(), dims)
+	targets := sortDimensions(slices.Map(func(v resizeVariant) Dimensions { return v.target }, variants))
+	settings := r.settings(variants)
+
+	resized, err := r.resizeInternal(ctx, input.Image, targets, settings)
 	if err != nil {
 		return nil, err
 	}
 
-	tagger, isTagger := r.dimensionProvider.(interface{ Tag(Dimensions) string })
+	images := make(map[Dimensions]image.Image, len(resized))
+	for _, rimg := range resized {
+		images[rimg.dimensions] = rimg.image
+	}
 
-	processed := make([]Processed, len(resized))
+	processed := make([]Processed, len(variants))
 	baseTags := input.Tags.Without(Original)
-	for i, rimg := range resized {
+	for i, v := range variants {
+		img := images[v.target]
 		tags := baseTags.With("resized")
+		meta := input.Meta.withBounds(img)
+
+		if len(v.names) > 0 {
+			tags = tags.Unset(SizeKey)
+			for _, name := range v.names {
+				tags = tags.With(FormatTag(Attr(SizeKey, name)))
+			}
+			meta[MetaSize] = v.names[0]
+		}
 
-		if isTagger {
-			tags = tags.With(fmt.Sprintf("size=%s", tagger.Tag(rimg.dimensions)))
+		if settings[v.target].sharpen != nil {
+			tags = tags.With(Sharpened)
+		}
+
+		if v.density > 0 {
+			tags = tags.Set(DensityKey, FormatDensity(v.density))
+			meta[MetaDensity] = v.density
 		}
 
 		processed[i] = Processed{
-			Image: rimg.image,
+			Image: img,
 			Tags:  tags,
+			Meta:  meta,
 		}
 	}
 
@@ -147,12 +462,24 @@ func (r *Resizer) Process(ctx ProcessorContext) ([]Processed, error) {
 	return append([]Processed{ctx.Image()}, processed...), nil
 }
 
-// DimensionName extracts the dimension name from the tags of a processed image.
-func DimensionName(tags Tags) string {
-	for _, tag := range tags {
-		if strings.HasPrefix(tag, "size=") {
-			return tag[5:]
+// dimensionNames returns the names of the given dimensions, if the
+// DimensionProvider provides names.
+func dimensionNames(provider DimensionProvider, dim Dimensions) []string {
+	switch p := provider.(type) {
+	case interface{ Names(Dimensions) []string }:
+		return p.Names(dim)
+	case interface{ Tag(Dimensions) string }:
+		if name := p.Tag(dim); name != "" {
+			return []string{name}
 		}
 	}
-	return ""
+	return nil
+}
+
+// DimensionName extracts the dimension name from the tags of a processed image.
+// If the image has multiple names (see [DimensionMap]), the first name is
+// returned.
+func DimensionName(tags Tags) string {
+	name, _ := tags.Get(SizeKey)
+	return name
 }
@@ -3,10 +3,13 @@ package image_test
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	stdimage "image"
 	"testing"
 
+	"github.com/disintegration/imaging"
+	"github.com/google/go-cmp/cmp"
 	"github.com/modernice/media-tools/image"
 	"github.com/modernice/media-tools/image/internal"
 )
@@ -150,6 +153,184 @@ func TestResizer_Process_Pipeline_DimensionMap(t *testing.T) {
 	}
 }
 
+func TestResizer_Process_canceled(t *testing.T) {
+	resizer := image.Resize(image.DimensionList{{360}, {640}, {960}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pctx := image.NewProcessorContext(ctx, image.Processed{Image: newExample(), Original: true})
+
+	if _, err := resizer.Process(pctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Process() should fail with %q; got %q", context.Canceled, err)
+	}
+}
+
+func TestDimensionMap_deterministic(t *testing.T) {
+	dimensions := image.DimensionMap{
+		"thumb": {64},
+		"xs":    {64},
+		"sm":    {128},
+		"md":    {256, 128},
+		"md2":   {256},
+		"lg":    {512},
+	}
+
+	wantDims := []image.Dimensions{{64}, {128}, {256}, {256, 128}, {512}}
+
+	for i := 0; i < 100; i++ {
+		if dims := dimensions.Dimensions(); !cmp.Equal(wantDims, dims) {
+			t.Fatalf("Dimensions() should return %v; got %v", wantDims, dims)
+		}
+
+		if tag := dimensions.Tag(image.Dimensions{64}); tag != "thumb" {
+			t.Fatalf("Tag() should return %q; got %q", "thumb", tag)
+		}
+
+		if names := dimensions.Names(image.Dimensions{64}); !cmp.Equal([]string{"thumb", "xs"}, names) {
+			t.Fatalf("Names() should return %v; got %v", []string{"thumb", "xs"}, names)
+		}
+	}
+}
+
+func TestResizer_Process_aliases(t *testing.T) {
+	dimensions := image.DimensionMap{
+		"thumb": {40},
+		"sm":    {40},
+		"md":    {60},
+	}
+
+	pipe := image.Pipeline{image.Resize(dimensions, image.DiscardInput(true))}
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 100, 80))
+
+	var first []image.Tags
+	for i := 0; i < 100; i++ {
+		result, err := pipe.Run(context.Background(), img)
+		if err != nil {
+			t.Fatalf("run pipeline: %v", err)
+		}
+
+		if len(result.Images) != 2 {
+			t.Fatalf("aliases should only be resized once; expected 2 images; got %d", len(result.Images))
+		}
+
+		tags := []image.Tags{result.Images[0].Tags, result.Images[1].Tags}
+
+		if first == nil {
+			first = tags
+		} else if !cmp.Equal(first, tags) {
+			t.Fatalf("run #%d returned different tags\n%s", i, cmp.Diff(first, tags))
+		}
+	}
+
+	if !first[0].Contains("size=sm") || !first[0].Contains("size=thumb") {
+		t.Fatalf("first image should have tags %q and %q; got %v", "size=sm", "size=thumb", first[0])
+	}
+
+	if name := image.DimensionName(first[0]); name != "sm" {
+		t.Fatalf("DimensionName() should return %q; got %q", "sm", name)
+	}
+
+	if !first[1].Contains("size=md") {
+		t.Fatalf("second image should have tag %q; got %v", "size=md", first[1])
+	}
+}
+
 func saveResized(t *testing.T, dim image.Dimensions, img stdimage.Image) {
 	saveOutImage(t, fmt.Sprintf("resized-%dx%d.jpg", dim.Width(), dim.Height()), img)
 }
+
+func TestResizer_Resize_Concurrency(t *testing.T) {
+	dimensions := image.DimensionList{{100, 100}, {300, 500}, {640}, {960}}
+	img := newExample()
+
+	want, err := image.Resize(dimensions).Resize(img)
+	if err != nil {
+		t.Fatalf("resize image: %v", err)
+	}
+
+	got, err := image.Resize(dimensions, image.Concurrency(0)).Resize(img)
+	if err != nil {
+		t.Fatalf("resize image concurrently: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d resized images; got %d", len(want), len(got))
+	}
+
+	for i := range want {
+		if !internal.EqualPixels(want[i], got[i]) {
+			t.Fatalf("concurrently resized image %d should equal the sequentially resized image", i)
+		}
+	}
+}
+
+func TestResizer_Resize_Progressive(t *testing.T) {
+	dimensions := image.DimensionList{{120}, {240}, {300, 200}, {480}, {960}}
+	img := newExample()
+
+	want, err := image.Resize(dimensions).Resize(img)
+	if err != nil {
+		t.Fatalf("resize image: %v", err)
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		got, err := image.Resize(dimensions, image.Progressive(2), image.Concurrency(concurrency)).Resize(img)
+		if err != nil {
+			t.Fatalf("resize image progressively: %v", err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("expected %d resized images; got %d", len(want), len(got))
+		}
+
+		for i := range want {
+			if got[i].Bounds() != want[i].Bounds() {
+				t.Fatalf("progressively resized image %d should have bounds %v; got %v", i, want[i].Bounds(), got[i].Bounds())
+			}
+
+			if !internal.SameImages(want[i], got[i]) {
+				t.Fatalf("progressively resized image %d should look like the directly resized image", i)
+			}
+		}
+	}
+}
+
+func TestResizer_Process_Concurrency_canceled(t *testing.T) {
+	resizer := image.Resize(image.DimensionList{{360}, {640}, {960}}, image.Concurrency(0), image.Progressive(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pctx := image.NewProcessorContext(ctx, image.Processed{Image: newExample(), Original: true})
+
+	if _, err := resizer.Process(pctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Process() should fail with %q; got %q", context.Canceled, err)
+	}
+}
+
+func BenchmarkResizer_Resize(b *testing.B) {
+	img := imaging.Resize(newExample(), 6000, 0, imaging.Linear)
+	dimensions := image.DimensionList{{320}, {640}, {960}, {1280}, {1920}, {2560}}
+
+	benchmarks := []struct {
+		name string
+		opts []image.ResizerOption
+	}{
+		{"direct", nil},
+		{"concurrent", []image.ResizerOption{image.Concurrency(0)}},
+		{"progressive", []image.ResizerOption{image.Progressive(2)}},
+		{"concurrent+progressive", []image.ResizerOption{image.Concurrency(0), image.Progressive(2)}},
+	}
+
+	for _, bm := range benchmarks {
+		resizer := image.Resize(dimensions, bm.opts...)
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := resizer.Resize(img); err != nil {
+					b.Fatalf("resize image: %v", err)
+				}
+			}
+		})
+	}
+}
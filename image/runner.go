@@ -0,0 +1,194 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RunOption is an option for [Pipeline.Run].
+type RunOption func(*runner)
+
+type runner struct {
+	middleware  []Middleware
+	hooks       []Hooks
+	progress    []func(Progress)
+	errorPolicy ErrorPolicy
+	lineage     *lineage
+
+	// path is the indexes of the stages that run the Pipeline of a nested
+	// runner, starting at the top-level Pipeline.
+	path []int
+
+	// skipped is called for every error that is skipped because of the
+	// ErrorPolicy.
+	skipped func(*ProcessorError)
+}
+
+type runnerKey struct{}
+
+func newRunner(opts ...RunOption) *runner {
+	r := runner{lineage: newLineage()}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return &r
+}
+
+// runnerOf returns the runner that is currently running a Pipeline, or a
+// default runner if ctx is not the context of a running Pipeline.
+func runnerOf(ctx context.Context) *runner {
+	if r, ok := ctx.Value(runnerKey{}).(*runner); ok {
+		return r
+	}
+	return newRunner()
+}
+
+// nested returns the runner for Pipelines that are run by Processors of the
+// Pipeline that r is running. Nested Pipelines inherit the middleware and the
+// error policy, but not the hooks of their parent. ctx is the context of the
+// Processor that runs the nested Pipeline.
+func (r *runner) nested(ctx context.Context) *runner {
+	path := r.path
+	if stage, ok := StageOf(ctx); ok {
+		path = append(append(make([]int, 0, len(r.path)+1), r.path...), stage.Index)
+	}
+
+	return &runner{
+		middleware:  r.middleware,
+		errorPolicy: r.errorPolicy,
+		skipped:     r.skipped,
+		lineage:     r.lineage,
+		path:        path,
+	}
+}
+
+func (r *runner) run(ctx context.Context, pipeline Pipeline, input Processed) ([]Processed, error) {
+	ctx = context.WithValue(ctx, runnerKey{}, r)
+
+	previous := []Processed{r.lineage.root(input)}
+
+	for i, processor := range pipeline {
+		stage := Stage{Index: i, Processor: processor}
+		sctx := context.WithValue(ctx, stageKey{}, stage)
+		wrapped := r.wrap(processor)
+
+		r.beforeStage(sctx, stage, previous)
+
+		progress := Progress{
+			Event:  StageStarted,
+			Stage:  i,
+			Stages: len(pipeline),
+			Images: len(previous),
+		}
+		r.report(progress)
+
+		_previous := previous
+		previous = make([]Processed, 0, len(_previous))
+
+		for j, img := range _previous {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("stage %d: processed %d of %d images: %w", i, j, len(_previous), err)
+			}
+
+			processed, err := r.process(sctx, stage, wrapped, img)
+			if err != nil && !r.skip(ctx, err) {
+				return nil, err
+			}
+			previous = append(previous, processed...)
+
+			progress.Event = ImageProcessed
+			progress.Image++
+			progress.Bytes = 0
+			for _, pimg := range processed {
+				progress.Bytes += bytesOf(pimg.Image)
+			}
+			progress.TotalBytes += progress.Bytes
+			r.report(progress)
+		}
+
+		progress.Event = StageFinished
+		progress.Bytes = 0
+		r.report(progress)
+	}
+
+	return previous, nil
+}
+
+func (r *runner) process(ctx context.Context, stage Stage, processor Processor, img Processed) ([]Processed, error) {
+	start := time.Now()
+	processed, err := processor.Process(NewProcessorContext(ctx, img))
+
+	var partial *PartialError
+	isPartial := errors.As(err, &partial)
+
+	if err == nil || isPartial {
+		if oerr := checkOriginals(processed); oerr != nil {
+			err, isPartial = oerr, false
+		}
+	}
+
+	if err == nil || isPartial {
+		r.lineage.derive(r.path, stage, img, processed)
+	}
+
+	event := ProcessEvent{
+		Stage:    stage,
+		Input:    img,
+		Output:   processed,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+
+	if err != nil {
+		r.onError(ctx, event)
+
+		if !isPartial {
+			return nil, newProcessorError(stage, img, err)
+		}
+
+		// Report each failed output separately and keep the produced images,
+		// which are dropped by the caller unless the error is skipped.
+		errs := make([]error, len(partial.Errs))
+		for i, e := range partial.Errs {
+			errs[i] = newProcessorError(stage, img, e)
+		}
+		return processed, errors.Join(errs...)
+	}
+
+	r.afterProcess(ctx, event)
+
+	return processed, nil
+}
+
+// skip returns whether err should be skipped according to the error policy.
+// Errors are never skipped if ctx is canceled.
+func (r *runner) skip(ctx context.Context, err error) bool {
+	perrs := processorErrors(err)
+	if r.errorPolicy == FailFast || ctx.Err() != nil || len(perrs) == 0 {
+		return false
+	}
+
+	if r.skipped != nil {
+		for _, perr := range perrs {
+			r.skipped(perr)
+		}
+	}
+
+	return true
+}
+
+func checkOriginals(processed []Processed) error {
+	var originalCount int
+	for _, pimg := range processed {
+		if pimg.Original {
+			originalCount++
+		}
+
+		if originalCount > 1 {
+			return ErrMultipleOriginals
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package image
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+var _ Processor = (*Sharpener)(nil)
+
+// Sharpened is the tag that is assigned to sharpened images.
+const Sharpened = "sharpened"
+
+// UnsharpMask sharpens images by adding the difference between an image and a
+// blurred copy of it back to the image.
+type UnsharpMask struct {
+	// Radius is the standard deviation of the Gaussian blur, in pixels.
+	// Typical values are between 0.5 and 2.
+	Radius float64
+
+	// Amount is the strength of the sharpening, e.g. 0.8 for 80%.
+	Amount float64
+
+	// Threshold is the minimum difference between a pixel and its blurred
+	// value (0-255) for the pixel to be sharpened. A threshold avoids
+	// sharpening noise in flat areas.
+	Threshold uint8
+}
+
+// Apply returns a sharpened copy of img. The alpha channel is not sharpened.
+func (m UnsharpMask) Apply(img image.Image) *image.NRGBA {
+	src := imaging.Clone(img)
+	if m.Radius <= 0 || m.Amount <= 0 {
+		return src
+	}
+
+	blurred := imaging.Blur(src, m.Radius)
+	out := image.NewNRGBA(src.Bounds())
+
+	for i := range src.Pix {
+		if i%4 == 3 {
+			out.Pix[i] = src.Pix[i]
+			continue
+		}
+
+		diff := float64(src.Pix[i]) - float64(blurred.Pix[i])
+		if math.Abs(diff) < float64(m.Threshold) {
+			out.Pix[i] = src.Pix[i]
+			continue
+		}
+
+		out.Pix[i] = clampUint8(float64(src.Pix[i]) + m.Amount*diff)
+	}
+
+	return out
+}
+
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
+
+// Sharpener is a [Processor] that sharpens images using an [UnsharpMask].
+type Sharpener struct {
+	mask UnsharpMask
+}
+
+// Sharpen returns a [*Sharpener] that sharpens images using the given
+// [UnsharpMask]. Sharpened images keep the tags of their input image and are
+// additionally tagged with [Sharpened]. To sharpen only some of the resized
+// images of a [Resizer], use [DimensionSharpen].
+func Sharpen(mask UnsharpMask) *Sharpener {
+	return &Sharpener{mask: mask}
+}
+
+// Process implements [Processor].
+func (s *Sharpener) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+	out := input.Derive(s.mask.Apply(input.Image))
+	out.Tags = out.Tags.With(Sharpened)
+	return []Processed{out}, nil
+}
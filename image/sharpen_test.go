@@ -0,0 +1,123 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/internal"
+)
+
+// newEdge returns an image whose left half is dark gray and whose right half
+// is light gray.
+func newEdge() *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			v := uint8(64)
+			if x >= 10 {
+				v = 192
+			}
+			img.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func TestUnsharpMask_Apply(t *testing.T) {
+	img := newEdge()
+
+	sharpened := image.UnsharpMask{Radius: 1, Amount: 1}.Apply(img)
+
+	if c := sharpened.NRGBAAt(9, 10); c.R >= 64 {
+		t.Fatalf("dark side of the edge should get darker; got %v", c)
+	}
+
+	if c := sharpened.NRGBAAt(10, 10); c.R <= 192 {
+		t.Fatalf("light side of the edge should get lighter; got %v", c)
+	}
+
+	if c := sharpened.NRGBAAt(0, 10); c.R != 64 {
+		t.Fatalf("flat areas should not change; got %v", c)
+	}
+
+	if c := sharpened.NRGBAAt(10, 10); c.A != 255 {
+		t.Fatalf("alpha should not change; got %v", c)
+	}
+}
+
+func TestUnsharpMask_Apply_threshold(t *testing.T) {
+	img := newEdge()
+
+	sharpened := image.UnsharpMask{Radius: 1, Amount: 1, Threshold: 255}.Apply(img)
+
+	if c := sharpened.NRGBAAt(9, 10); c.R != 64 {
+		t.Fatalf("differences below the threshold should not be sharpened; got %v", c)
+	}
+}
+
+func TestSharpener_Process(t *testing.T) {
+	pipe := image.Pipeline{image.Sharpen(image.UnsharpMask{Radius: 1, Amount: 1})}
+
+	result, err := pipe.Run(context.Background(), newEdge())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	if !result.Images[0].Tags.Contains(image.Sharpened) {
+		t.Fatalf("sharpened image should have tag %q; got %v", image.Sharpened, result.Images[0].Tags)
+	}
+}
+
+func TestResizer_Process_DimensionSharpen(t *testing.T) {
+	dims := image.DimensionMap{"thumb": {50}, "lg": {400}}
+	mask := image.UnsharpMask{Radius: 0.8, Amount: 1.2, Threshold: 2}
+
+	pipe := image.Pipeline{
+		image.Resize(
+			dims,
+			image.DimensionFilter("thumb", imaging.CatmullRom),
+			image.DimensionSharpen("thumb", mask),
+			image.DiscardInput(true),
+		),
+	}
+
+	result, err := pipe.Run(context.Background(), newExample())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	thumb, err := result.Single("size=thumb")
+	if err != nil {
+		t.Fatalf("find thumbnail: %v", err)
+	}
+
+	if !thumb.Tags.Contains(image.Sharpened) {
+		t.Fatalf("thumbnail should have tag %q; got %v", image.Sharpened, thumb.Tags)
+	}
+
+	want := mask.Apply(imaging.Resize(newExample(), 50, 0, imaging.CatmullRom))
+	if !internal.EqualPixels(thumb.Image, want) {
+		t.Fatalf("thumbnail should be resized with the configured filter and sharpened")
+	}
+
+	lg, err := result.Single("size=lg")
+	if err != nil {
+		t.Fatalf("find large image: %v", err)
+	}
+
+	if lg.Tags.Contains(image.Sharpened) {
+		t.Fatalf("large image should not have tag %q", image.Sharpened)
+	}
+
+	if !internal.EqualPixels(lg.Image, imaging.Resize(newExample(), 400, 0, imaging.Lanczos)) {
+		t.Fatalf("large image should be resized with the default filter")
+	}
+}
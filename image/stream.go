@@ -0,0 +1,130 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"image"
+)
+
+// Streamed is an element of the stream that is returned by [Pipeline.Stream].
+// Either Processed or Err is set.
+type Streamed struct {
+	// Processed is an image that left the last [Processor] of the [Pipeline].
+	Processed Processed
+
+	// Err is the error that stopped the [Pipeline].
+	Err error
+}
+
+// Stream runs the pipeline on an image and returns a channel of the processed
+// images. Unlike [Pipeline.Run], which processes the pipeline stage by stage,
+// Stream passes each image through all remaining stages before processing the
+// next one, so that the first images are streamed while the others are still
+// being processed. The images are streamed in the same order as they would be
+// returned by [Pipeline.Run].
+//
+// If a [Processor] fails, the error is sent as the last element of the
+// stream. If the [ErrorPolicy] of the pipeline is [SkipFailed] or
+// [CollectErrors], the errors of skipped images are sent as they occur and
+// the pipeline continues. The channel is closed when the pipeline is done or
+// when ctx is canceled. Callers that stop receiving before the channel is
+// closed must cancel ctx to release the goroutine that runs the pipeline.
+//
+// [Hooks.BeforeStage] is not called and progress is not reported for streamed
+// pipelines because their stages overlap. All other options of [Pipeline.Run]
+// are supported.
+func (pipeline Pipeline) Stream(ctx context.Context, img image.Image, opts ...RunOption) <-chan Streamed {
+	out := make(chan Streamed)
+
+	r := newRunner(opts...)
+	r.progress = nil
+	for i := range r.hooks {
+		r.hooks[i].BeforeStage = nil
+	}
+
+	go func() {
+		defer close(out)
+
+		emit := func(s Streamed) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case out <- s:
+				return true
+			}
+		}
+
+		r.skipped = func(err *ProcessorError) {
+			emit(Streamed{Err: err})
+		}
+
+		if err := r.stream(ctx, pipeline, newOriginal(img), emit); err != nil {
+			select {
+			case <-ctx.Done():
+				// Deliver the error if the receiver is still waiting, but
+				// don't block if it is gone.
+				select {
+				case out <- Streamed{Err: err}:
+				default:
+				}
+			case out <- Streamed{Err: err}:
+			}
+		}
+	}()
+
+	return out
+}
+
+func (r *runner) stream(ctx context.Context, pipeline Pipeline, input Processed, emit func(Streamed) bool) error {
+	ctx = context.WithValue(ctx, runnerKey{}, r)
+
+	stages := make([]Stage, len(pipeline))
+	wrapped := make([]Processor, len(pipeline))
+	for i, processor := range pipeline {
+		stages[i] = Stage{Index: i, Processor: processor}
+		wrapped[i] = r.wrap(processor)
+	}
+
+	var next func(int, Processed) error
+	next = func(i int, img Processed) error {
+		if i == len(pipeline) {
+			if err := ctx.Err(); err != nil {
+				return afterStage(i-1, err)
+			}
+			if !emit(Streamed{Processed: img}) {
+				return afterStage(i-1, ctx.Err())
+			}
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("stage %d: %w", i, err)
+		}
+
+		sctx := context.WithValue(ctx, stageKey{}, stages[i])
+
+		processed, err := r.process(sctx, stages[i], wrapped[i], img)
+		if err != nil && !r.skip(ctx, err) {
+			return err
+		}
+
+		for _, pimg := range processed {
+			if err := next(i+1, pimg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return next(0, r.lineage.root(input))
+}
+
+// afterStage wraps an error that occurred after the stage with the given
+// index, when a processed image was emitted.
+func afterStage(stage int, err error) error {
+	if stage < 0 {
+		return fmt.Errorf("emit image: %w", err)
+	}
+	return fmt.Errorf("emit image after stage %d: %w", stage, err)
+}
@@ -0,0 +1,144 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modernice/media-tools/image"
+	"github.com/modernice/media-tools/image/compression"
+	"github.com/modernice/media-tools/image/internal"
+)
+
+func TestPipeline_Stream(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}}),
+		image.CompressMany([]image.Compression{compression.JPEG(75), compression.JPEG(50)}),
+	}
+
+	original := newExample()
+
+	result, err := pipe.Run(context.Background(), original)
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	var streamed []image.Processed
+	for s := range pipe.Stream(context.Background(), original) {
+		if s.Err != nil {
+			t.Fatalf("stream pipeline: %v", s.Err)
+		}
+		streamed = append(streamed, s.Processed)
+	}
+
+	if len(streamed) != len(result.Images) {
+		t.Fatalf("stream should return %d images; got %d", len(result.Images), len(streamed))
+	}
+
+	for i, img := range result.Images {
+		if !cmp.Equal(img.Tags, streamed[i].Tags) {
+			t.Fatalf("streamed image #%d should have the same tags as the image returned by Run()\n%s", i, cmp.Diff(img.Tags, streamed[i].Tags))
+		}
+
+		if !internal.EqualImages(img.Image, streamed[i].Image) {
+			t.Fatalf("streamed image #%d should equal the image returned by Run()", i)
+		}
+	}
+}
+
+func TestPipeline_Stream_early(t *testing.T) {
+	release := make(chan struct{})
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "xl": {960}}, image.DiscardInput(true)),
+		image.WhenTagged(image.NewTags("size=xl"), image.ProcessorFunc(func(ctx image.ProcessorContext) ([]image.Processed, error) {
+			<-release
+			return []image.Processed{ctx.Image()}, nil
+		})),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := pipe.Stream(ctx, newExample())
+
+	select {
+	case s := <-stream:
+		if s.Err != nil {
+			t.Fatalf("stream pipeline: %v", s.Err)
+		}
+		if !s.Processed.Tags.Contains("size=sm") {
+			t.Fatalf("first streamed image should have tag %q; got %v", "size=sm", s.Processed.Tags)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("first image should be streamed before the last image is processed")
+	}
+
+	close(release)
+
+	s, ok := <-stream
+	if !ok || !s.Processed.Tags.Contains("size=xl") {
+		t.Fatalf("second streamed image should have tag %q", "size=xl")
+	}
+
+	if _, ok := <-stream; ok {
+		t.Fatalf("stream should be closed")
+	}
+}
+
+func TestPipeline_Stream_error(t *testing.T) {
+	mockError := errors.New("mock error")
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "xl": {960}}),
+		image.WhenTagged(image.NewTags("size=xl"), image.ProcessorFunc(func(image.ProcessorContext) ([]image.Processed, error) {
+			return nil, mockError
+		})),
+	}
+
+	var (
+		images int
+		err    error
+	)
+	for s := range pipe.Stream(context.Background(), newExample()) {
+		if s.Err != nil {
+			err = s.Err
+			continue
+		}
+		images++
+	}
+
+	if images != 2 {
+		t.Fatalf("stream should return %d images before the error; got %d", 2, images)
+	}
+
+	if !errors.Is(err, mockError) {
+		t.Fatalf("stream should fail with %q; got %q", mockError, err)
+	}
+}
+
+func TestPipeline_Stream_cancel(t *testing.T) {
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {360}, "md": {640}, "lg": {960}}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := pipe.Stream(ctx, newExample())
+	<-stream
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("stream should be closed after the context is canceled")
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package image
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/modernice/media-tools/internal/slices"
+)
+
+// Tags is a list of tags that Processors assigned to images in a [Pipeline].
+type Tags []string
+
+// NewTags returns the given tags as [Tags]. Duplicates are removed.
+func NewTags(tags ...string) Tags {
+	return slices.Unique(Tags(tags))
+}
+
+// Match returns the tags that match the given regular expression.
+func (tags Tags) Match(re *regexp.Regexp) []string {
+	var out []string
+	for _, tag := range tags {
+		if re.MatchString(tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// Contains returns whether a tag is contained within tags.
+func (tags Tags) Contains(tag string) bool {
+	return slices.Contains(tag, tags)
+}
+
+// With appends additional tags and returns the new [Tags]. Duplicate tags are removed.
+func (tags Tags) With(add ...string) Tags {
+	return slices.Unique(append(tags, add...))
+}
+
+// Without returns a copy of tags without the given tags.
+func (tags Tags) Without(remove ...string) Tags {
+	out := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		if slices.Contains(tag, remove) {
+			continue
+		}
+		out = append(out, tag)
+	}
+	return out
+}
+
+// Attribute is a key/value pair of a structured tag. Structured tags have the
+// form "key=value[,key=value...]", for example "compression=jpeg,quality=75".
+// The key of the first attribute is the key of the tag.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr returns an [Attribute] with the given key and value.
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// FormatTag formats a structured tag from the given attributes. Commas,
+// equals signs and backslashes within keys and values are escaped with a
+// backslash, so that the tag can be parsed by [ParseTag] without loss.
+func FormatTag(attrs ...Attribute) string {
+	var b strings.Builder
+	for i, attr := range attrs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeEscaped(&b, attr.Key)
+		b.WriteByte('=')
+		writeEscaped(&b, attr.Value)
+	}
+	return b.String()
+}
+
+func writeEscaped(b *strings.Builder, s string) {
+	for _, r := range s {
+		if r == ',' || r == '=' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+}
+
+// ParseTag parses a structured tag into its attributes. If the tag is not a
+// structured tag, i.e. one of its comma-separated parts has no unescaped
+// equals sign, ParseTag returns false.
+func ParseTag(tag string) ([]Attribute, bool) {
+	var (
+		attrs   []Attribute
+		current strings.Builder
+		key     string
+		hasKey  bool
+		escaped bool
+	)
+
+	flush := func() bool {
+		if !hasKey {
+			return false
+		}
+		attrs = append(attrs, Attribute{Key: key, Value: current.String()})
+		current.Reset()
+		hasKey = false
+		return true
+	}
+
+	for _, r := range tag {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '=' && !hasKey:
+			key = current.String()
+			current.Reset()
+			hasKey = true
+		case r == ',':
+			if !flush() {
+				return nil, false
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped || !flush() {
+		return nil, false
+	}
+
+	return attrs, true
+}
+
+// TagKey returns the key of a structured tag, or an empty string if tag is
+// not a structured tag.
+func TagKey(tag string) string {
+	attrs, ok := ParseTag(tag)
+	if !ok {
+		return ""
+	}
+	return attrs[0].Key
+}
+
+// Get returns the value of the first structured tag with the given key. For
+// example, Get("size") returns "sm" for the tag "size=sm", and Get("compression")
+// returns "jpeg" for the tag "compression=jpeg,quality=75".
+func (tags Tags) Get(key string) (string, bool) {
+	for _, tag := range tags {
+		if attrs, ok := ParseTag(tag); ok && attrs[0].Key == key {
+			return attrs[0].Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns the values of all structured tags with the given key.
+func (tags Tags) Values(key string) []string {
+	var out []string
+	for _, tag := range tags {
+		if attrs, ok := ParseTag(tag); ok && attrs[0].Key == key {
+			out = append(out, attrs[0].Value)
+		}
+	}
+	return out
+}
+
+// Attributes returns all attributes of the first structured tag with the given
+// key, including the key itself. For example, Attributes("compression") returns
+// {"compression": "jpeg", "quality": "75"} for the tag
+// "compression=jpeg,quality=75". If there is no such tag, nil is returned.
+func (tags Tags) Attributes(key string) map[string]string {
+	for _, tag := range tags {
+		attrs, ok := ParseTag(tag)
+		if !ok || attrs[0].Key != key {
+			continue
+		}
+
+		out := make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			out[attr.Key] = attr.Value
+		}
+		return out
+	}
+	return nil
+}
+
+// Set returns a copy of tags where all structured tags with the given key are
+// replaced by a single tag with the given value and additional attributes.
+func (tags Tags) Set(key, value string, attrs ...Attribute) Tags {
+	return tags.Unset(key).With(FormatTag(append([]Attribute{Attr(key, value)}, attrs...)...))
+}
+
+// Unset returns a copy of tags without the structured tags with the given key.
+func (tags Tags) Unset(key string) Tags {
+	out := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		if attrs, ok := ParseTag(tag); ok && attrs[0].Key == key {
+			continue
+		}
+		out = append(out, tag)
+	}
+	return out
+}
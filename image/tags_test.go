@@ -0,0 +1,104 @@
+package image_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modernice/media-tools/image"
+)
+
+func TestFormatTag_ParseTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []image.Attribute
+		want  string
+	}{
+		{
+			name:  "single",
+			attrs: []image.Attribute{image.Attr("size", "sm")},
+			want:  "size=sm",
+		},
+		{
+			name:  "multiple",
+			attrs: []image.Attribute{image.Attr("compression", "jpeg"), image.Attr("quality", "75")},
+			want:  "compression=jpeg,quality=75",
+		},
+		{
+			name:  "escaped",
+			attrs: []image.Attribute{image.Attr("na,me", `a=b\c`), image.Attr("x", "")},
+			want:  `na\,me=a\=b\\c,x=`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := image.FormatTag(tt.attrs...)
+			if tag != tt.want {
+				t.Fatalf("FormatTag() should return %q; got %q", tt.want, tag)
+			}
+
+			attrs, ok := image.ParseTag(tag)
+			if !ok {
+				t.Fatalf("ParseTag(%q) failed", tag)
+			}
+
+			if !cmp.Equal(tt.attrs, attrs) {
+				t.Fatalf("ParseTag() should return the formatted attributes\n%s", cmp.Diff(tt.attrs, attrs))
+			}
+		})
+	}
+}
+
+func TestParseTag_invalid(t *testing.T) {
+	for _, tag := range []string{"", "resized", "size=sm,resized", `size=sm\`} {
+		if _, ok := image.ParseTag(tag); ok {
+			t.Fatalf("ParseTag(%q) should fail", tag)
+		}
+	}
+}
+
+func TestTags_Get(t *testing.T) {
+	tags := image.NewTags("resized", "size=sm", "compression=jpeg,quality=75")
+
+	if v, ok := tags.Get("size"); !ok || v != "sm" {
+		t.Fatalf("Get(%q) should return %q; got %q", "size", "sm", v)
+	}
+
+	if v, ok := tags.Get("compression"); !ok || v != "jpeg" {
+		t.Fatalf("Get(%q) should return %q; got %q", "compression", "jpeg", v)
+	}
+
+	if _, ok := tags.Get("quality"); ok {
+		t.Fatalf("Get(%q) should not return attributes of other tags", "quality")
+	}
+
+	if _, ok := tags.Get("resized"); ok {
+		t.Fatalf("Get(%q) should not return unstructured tags", "resized")
+	}
+
+	want := map[string]string{"compression": "jpeg", "quality": "75"}
+	if attrs := tags.Attributes("compression"); !cmp.Equal(want, attrs) {
+		t.Fatalf("Attributes(%q) returned wrong attributes\n%s", "compression", cmp.Diff(want, attrs))
+	}
+}
+
+func TestTags_Set(t *testing.T) {
+	tags := image.NewTags("resized", "size=sm", "size=thumb")
+
+	tags = tags.Set("size", "md")
+
+	want := image.NewTags("resized", "size=md")
+	if !cmp.Equal(want, tags) {
+		t.Fatalf("Set() returned wrong tags\n%s", cmp.Diff(want, tags))
+	}
+
+	tags = tags.Set("compression", "jpeg", image.Attr("quality", "50"))
+
+	if !tags.Contains("compression=jpeg,quality=50") {
+		t.Fatalf("Set() should add tag %q; got %v", "compression=jpeg,quality=50", tags)
+	}
+
+	if tags = tags.Unset("size"); tags.Contains("size=md") {
+		t.Fatalf("Unset() should remove tag %q; got %v", "size=md", tags)
+	}
+}
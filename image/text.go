@@ -0,0 +1,343 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var _ Processor = (*TextOverlay)(nil)
+
+// Captioned is the tag that is assigned to images with a text overlay.
+const Captioned = "captioned"
+
+// Alignment is the horizontal alignment of the lines of a text.
+type Alignment int
+
+const (
+	// AlignCenter centers the lines of a text.
+	AlignCenter Alignment = iota
+	// AlignLeft aligns the lines of a text to the left.
+	AlignLeft
+	// AlignRight aligns the lines of a text to the right.
+	AlignRight
+)
+
+// defaultFont is the bundled fallback font, Go Regular.
+var defaultFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// TextOverlay is a [Processor] that draws text onto images.
+type TextOverlay struct {
+	text      string
+	fontData  []byte
+	font      *opentype.Font
+	size      float64
+	color     color.Color
+	box       image.Rectangle
+	margin    int
+	gravity   Gravity
+	align     Alignment
+	shadow    color.Color
+	shadowOff image.Point
+	stroke    color.Color
+	strokeW   int
+	bg        color.Color
+	padding   int
+}
+
+// TextOption is an option for a [*TextOverlay].
+type TextOption func(*TextOverlay)
+
+// TextFont returns a TextOption that sets the OpenType font (TTF or OTF) to
+// render the text with. By default, the bundled Go Regular font is used.
+func TextFont(data []byte) TextOption {
+	return func(t *TextOverlay) {
+		t.fontData = data
+	}
+}
+
+// TextSize returns a TextOption that sets the font size, in pixels. Defaults
+// to 24.
+func TextSize(px float64) TextOption {
+	return func(t *TextOverlay) {
+		t.size = px
+	}
+}
+
+// TextColor returns a TextOption that sets the color of the text. Defaults to
+// [color.White].
+func TextColor(c color.Color) TextOption {
+	return func(t *TextOverlay) {
+		t.color = c
+	}
+}
+
+// TextBox returns a TextOption that sets the box, in pixels relative to the
+// top-left corner of the image, within which the text is wrapped and
+// positioned. Defaults to the whole image.
+func TextBox(rect image.Rectangle) TextOption {
+	return func(t *TextOverlay) {
+		t.box = rect
+	}
+}
+
+// TextMargin returns a TextOption that insets the text box by the given number
+// of pixels on each side.
+func TextMargin(px int) TextOption {
+	return func(t *TextOverlay) {
+		t.margin = px
+	}
+}
+
+// TextGravity returns a TextOption that sets the position of the text within
+// its box. Defaults to [GravitySouth].
+func TextGravity(g Gravity) TextOption {
+	return func(t *TextOverlay) {
+		t.gravity = g
+	}
+}
+
+// TextAlign returns a TextOption that sets the alignment of the lines of the
+// text. Defaults to [AlignCenter].
+func TextAlign(a Alignment) TextOption {
+	return func(t *TextOverlay) {
+		t.align = a
+	}
+}
+
+// TextShadow returns a TextOption that draws a shadow of the given color
+// behind the text, moved by the given offset, in pixels.
+func TextShadow(c color.Color, offset image.Point) TextOption {
+	return func(t *TextOverlay) {
+		t.shadow = c
+		t.shadowOff = offset
+	}
+}
+
+// TextStroke returns a TextOption that outlines the text with the given color
+// and width, in pixels.
+func TextStroke(c color.Color, width int) TextOption {
+	return func(t *TextOverlay) {
+		t.stroke = c
+		t.strokeW = width
+	}
+}
+
+// TextBackground returns a TextOption that draws a box of the given color
+// behind the text. padding is the space between the text and the edges of the
+// box, in pixels.
+func TextBackground(c color.Color, padding int) TextOption {
+	return func(t *TextOverlay) {
+		t.bg = c
+		t.padding = padding
+	}
+}
+
+// Text returns a [*TextOverlay] that draws the given text onto images. The
+// text is wrapped at word boundaries to fit the width of its box; newlines
+// start new lines. Images with a text overlay keep the tags of their input
+// image and are additionally tagged with [Captioned].
+//
+// The font is parsed once by Text, so an invalid [TextFont] is reported by
+// Text instead of by every call to Process.
+func Text(text string, opts ...TextOption) (*TextOverlay, error) {
+	t := &TextOverlay{
+		text:    text,
+		size:    24,
+		color:   color.White,
+		gravity: GravitySouth,
+		align:   AlignCenter,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	var err error
+	if t.fontData != nil {
+		t.font, err = opentype.Parse(t.fontData)
+	} else {
+		t.font, err = defaultFont()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+
+	return t, nil
+}
+
+// Process implements [Processor].
+func (t *TextOverlay) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	img, err := t.draw(input.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	out := input.Derive(img)
+	out.Tags = out.Tags.With(Captioned)
+
+	return []Processed{out}, nil
+}
+
+func (t *TextOverlay) draw(img image.Image) (*image.NRGBA, error) {
+	if t.font == nil {
+		return nil, errors.New("text overlay has no font; create it using Text")
+	}
+
+	face, err := opentype.NewFace(t.font, &opentype.FaceOptions{Size: t.size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("create font face: %w", err)
+	}
+	defer face.Close()
+
+	dst := imaging.Clone(img)
+
+	box := dst.Bounds()
+	if !t.box.Empty() {
+		box = t.box.Intersect(box)
+	}
+	box = box.Inset(t.margin)
+
+	inner := box.Inset(t.padding)
+	lines := wrapText(face, t.text, inner.Dx())
+	if len(lines) == 0 {
+		return dst, nil
+	}
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	ascent := metrics.Ascent.Ceil()
+
+	widths := make([]int, len(lines))
+	var blockWidth int
+	for i, line := range lines {
+		widths[i] = font.MeasureString(face, line).Ceil()
+		blockWidth = max(blockWidth, widths[i])
+	}
+
+	block := image.Point{X: blockWidth, Y: len(lines) * lineHeight}
+	pos := t.gravity.position(inner, block, image.Point{})
+
+	if t.bg != nil {
+		rect := image.Rectangle{Min: pos, Max: pos.Add(block)}.Inset(-t.padding)
+		draw.Draw(dst, rect, image.NewUniform(t.bg), image.Point{}, draw.Over)
+	}
+
+	// Render the glyphs once into a mask, which is then composited for the
+	// shadow, the stroke and the text itself.
+	mask := image.NewAlpha(dst.Bounds())
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face}
+	for i, line := range lines {
+		x := pos.X
+		switch t.align {
+		case AlignCenter:
+			x += (blockWidth - widths[i]) / 2
+		case AlignRight:
+			x += blockWidth - widths[i]
+		}
+		y := pos.Y + i*lineHeight + ascent
+		d.Dot = fixed.P(x, y)
+		d.DrawString(line)
+	}
+
+	bounds := dst.Bounds()
+
+	if t.shadow != nil {
+		draw.DrawMask(dst, bounds, image.NewUniform(t.shadow), image.Point{}, mask, bounds.Min.Sub(t.shadowOff), draw.Over)
+	}
+
+	if t.stroke != nil && t.strokeW > 0 {
+		draw.DrawMask(dst, bounds, image.NewUniform(t.stroke), image.Point{}, dilate(mask, t.strokeW), bounds.Min, draw.Over)
+	}
+
+	draw.DrawMask(dst, bounds, image.NewUniform(t.color), image.Point{}, mask, bounds.Min, draw.Over)
+
+	return dst, nil
+}
+
+// dilate returns a copy of mask in which each pixel has the maximum alpha of
+// the pixels of mask within the given radius.
+func dilate(mask *image.Alpha, radius int) *image.Alpha {
+	bounds := mask.Bounds()
+	out := image.NewAlpha(bounds)
+
+	// Only the area around the ink of the mask can change.
+	var ink image.Rectangle
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if mask.AlphaAt(x, y).A > 0 {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	area := ink.Inset(-radius).Intersect(bounds)
+
+	var offsets []image.Point
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy <= radius*radius {
+				offsets = append(offsets, image.Pt(dx, dy))
+			}
+		}
+	}
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			var a uint8
+			for _, off := range offsets {
+				p := image.Pt(x+off.X, y+off.Y)
+				if p.In(ink) {
+					a = max(a, mask.AlphaAt(p.X, p.Y).A)
+				}
+			}
+			out.SetAlpha(x, y, color.Alpha{A: a})
+		}
+	}
+
+	return out
+}
+
+// wrapText splits text into lines that fit the given width. Words that are
+// wider than the width are put on their own line.
+func wrapText(face font.Face, text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if line == "" || font.MeasureString(face, candidate).Ceil() <= width {
+				line = candidate
+				continue
+			}
+
+			lines = append(lines, line)
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	// Remove trailing empty lines.
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,159 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+func drawText(t *testing.T, text string, opts ...image.TextOption) *stdimage.NRGBA {
+	t.Helper()
+
+	overlay, err := image.Text(text, opts...)
+	if err != nil {
+		t.Fatalf("create text overlay: %v", err)
+	}
+
+	pipe := image.Pipeline{overlay}
+
+	result, err := pipe.Run(context.Background(), newUniform(200, 100, color.Black))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	if !result.Images[0].Tags.Contains(image.Captioned) {
+		t.Fatalf("image should have tag %q; got %v", image.Captioned, result.Images[0].Tags)
+	}
+
+	return result.Images[0].Image.(*stdimage.NRGBA)
+}
+
+// inkBounds returns the bounds of the pixels of img that have the given color.
+func inkBounds(img *stdimage.NRGBA, c color.NRGBA) stdimage.Rectangle {
+	var out stdimage.Rectangle
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if img.NRGBAAt(x, y) == c {
+				out = out.Union(stdimage.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return out
+}
+
+var white = color.NRGBA{255, 255, 255, 255}
+
+func TestTextOverlay_Process(t *testing.T) {
+	img := drawText(t, "Hello", image.TextMargin(5))
+
+	ink := inkBounds(img, white)
+	if ink.Empty() {
+		t.Fatalf("text should be drawn")
+	}
+
+	if ink.Min.Y < 50 || ink.Max.Y > 95 {
+		t.Fatalf("text should be drawn at the bottom of the image; got %v", ink)
+	}
+
+	if center := (ink.Min.X + ink.Max.X) / 2; center < 90 || center > 110 {
+		t.Fatalf("text should be horizontally centered; got %v", ink)
+	}
+}
+
+func TestTextOverlay_Process_wrap(t *testing.T) {
+	single := inkBounds(drawText(t, "one two three four", image.TextSize(16)), white)
+	wrapped := inkBounds(drawText(t, "one two three four", image.TextSize(16), image.TextBox(stdimage.Rect(0, 0, 60, 100))), white)
+
+	if wrapped.Dy() < 2*single.Dy() {
+		t.Fatalf("wrapped text should span multiple lines; got %v, single line %v", wrapped, single)
+	}
+
+	if wrapped.Max.X > 60 {
+		t.Fatalf("wrapped text should fit into its box; got %v", wrapped)
+	}
+
+	newlines := inkBounds(drawText(t, "one\ntwo", image.TextSize(16)), white)
+	if newlines.Dy() <= single.Dy() || newlines.Dx() >= single.Dx() {
+		t.Fatalf("newlines should start new lines; got %v", newlines)
+	}
+}
+
+func TestTextOverlay_Process_align(t *testing.T) {
+	text := "a long first line\nshort"
+	opts := []image.TextOption{image.TextSize(16), image.TextGravity(image.GravityNorthWest)}
+
+	left := drawText(t, text, append(opts, image.TextAlign(image.AlignLeft))...)
+	right := drawText(t, text, append(opts, image.TextAlign(image.AlignRight))...)
+
+	// Compare the second line, which is shorter than the first.
+	secondLine := stdimage.Rect(0, 20, 200, 40)
+	leftInk := inkBounds(left.SubImage(secondLine).(*stdimage.NRGBA), white)
+	rightInk := inkBounds(right.SubImage(secondLine).(*stdimage.NRGBA), white)
+
+	if leftInk.Empty() || rightInk.Empty() {
+		t.Fatalf("second line should be drawn")
+	}
+
+	if leftInk.Min.X > 3 {
+		t.Fatalf("left-aligned line should start at the left edge; got %v", leftInk)
+	}
+
+	if rightInk.Min.X <= leftInk.Min.X+10 {
+		t.Fatalf("right-aligned line should be moved to the right; got %v, left-aligned %v", rightInk, leftInk)
+	}
+}
+
+func TestTextOverlay_Process_effects(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	green := color.NRGBA{0, 255, 0, 255}
+
+	img := drawText(t, "Hello",
+		image.TextSize(32),
+		image.TextGravity(image.GravityCenter),
+		image.TextBackground(green, 10),
+		image.TextShadow(red, stdimage.Pt(6, 6)),
+		image.TextStroke(blue, 2),
+	)
+
+	bg := inkBounds(img, green)
+	text := inkBounds(img, white)
+
+	if bg.Empty() || !text.In(bg) {
+		t.Fatalf("background box %v should contain the text %v", bg, text)
+	}
+
+	if shadow := inkBounds(img, red); shadow.Empty() || shadow.Max.X <= text.Max.X {
+		t.Fatalf("shadow should be drawn with the given offset; got %v, text %v", shadow, text)
+	}
+
+	stroke := inkBounds(img, blue)
+	if stroke.Empty() || stroke.Min.X >= text.Min.X {
+		t.Fatalf("stroke should surround the text; got %v, text %v", stroke, text)
+	}
+
+	if stroke.Min.X < text.Min.X-3 || stroke.Min.Y < text.Min.Y-3 {
+		t.Fatalf("stroke should have a width of 2 pixels; got %v, text %v", stroke, text)
+	}
+}
+
+func TestTextOverlay_Process_font(t *testing.T) {
+	regular := inkBounds(drawText(t, "Hello", image.TextSize(32)), white)
+	bold := inkBounds(drawText(t, "Hello", image.TextSize(32), image.TextFont(gobold.TTF)), white)
+
+	if bold.Dx() <= regular.Dx() {
+		t.Fatalf("bold text should be wider than regular text; got %v, regular %v", bold, regular)
+	}
+
+	if _, err := image.Text("Hello", image.TextFont([]byte("not a font"))); err == nil {
+		t.Fatalf("Text() should fail for an invalid font")
+	}
+}
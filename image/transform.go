@@ -0,0 +1,157 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+var (
+	_ Processor = (*Cropper)(nil)
+	_ Processor = (*Rotator)(nil)
+	_ Processor = (*Flipper)(nil)
+)
+
+// ErrEmptyCrop is returned by a [*Cropper] if the crop rectangle does not
+// overlap the image.
+var ErrEmptyCrop = errors.New("crop rectangle does not overlap the image")
+
+const (
+	// Cropped is the tag that is assigned to cropped images.
+	Cropped = "cropped"
+
+	// Rotated is the tag that is assigned to rotated images.
+	Rotated = "rotated"
+
+	// Flipped is the tag that is assigned to flipped images.
+	Flipped = "flipped"
+)
+
+// Cropper is a [Processor] that crops images to a rectangle.
+type Cropper struct {
+	rect func(bounds image.Rectangle) image.Rectangle
+}
+
+// Crop returns a [*Cropper] that crops images to the given rectangle, in
+// pixels relative to the top-left corner of the image. The rectangle is
+// clipped to the bounds of the image; if it doesn't overlap the image, the
+// [*Cropper] fails with [ErrEmptyCrop].
+func Crop(rect image.Rectangle) *Cropper {
+	return &Cropper{rect: func(image.Rectangle) image.Rectangle {
+		return rect
+	}}
+}
+
+// CropRelative returns a [*Cropper] that crops images to a rectangle that is
+// relative to the size of the image. x, y, width and height are fractions of
+// the image size between 0 and 1, e.g. CropRelative(0.25, 0.25, 0.5, 0.5)
+// crops the center of the image.
+func CropRelative(x, y, width, height float64) *Cropper {
+	return &Cropper{rect: func(bounds image.Rectangle) image.Rectangle {
+		w, h := float64(bounds.Dx()), float64(bounds.Dy())
+		return image.Rect(
+			int(x*w+0.5),
+			int(y*h+0.5),
+			int((x+width)*w+0.5),
+			int((y+height)*h+0.5),
+		)
+	}}
+}
+
+// Process implements [Processor]. Cropped images keep the tags of their input
+// image and are additionally tagged with [Cropped].
+func (c *Cropper) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+	bounds := input.Image.Bounds()
+
+	rect := c.rect(bounds).Add(bounds.Min).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("crop %v: %w", c.rect(bounds), ErrEmptyCrop)
+	}
+
+	out := input.Derive(imaging.Crop(input.Image, rect))
+	out.Tags = out.Tags.With(Cropped)
+
+	return []Processed{out}, nil
+}
+
+// Rotator is a [Processor] that rotates images.
+type Rotator struct {
+	angle      float64
+	background color.Color
+}
+
+// RotateOption is an option for a [*Rotator].
+type RotateOption func(*Rotator)
+
+// RotateBackground returns a RotateOption that sets the color that fills the
+// uncovered areas of images that are rotated by an angle that is not a
+// multiple of 90 degrees. Defaults to [color.Transparent].
+func RotateBackground(c color.Color) RotateOption {
+	return func(r *Rotator) {
+		r.background = c
+	}
+}
+
+// Rotate returns a [*Rotator] that rotates images counter-clockwise by the
+// given angle, in degrees. Rotations by multiples of 90 degrees are lossless.
+// For other angles, the rotated image is enlarged to fit the whole input image.
+func Rotate(angle float64, opts ...RotateOption) *Rotator {
+	r := &Rotator{
+		angle:      angle,
+		background: color.Transparent,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Process implements [Processor]. Rotated images keep the tags of their input
+// image and are additionally tagged with [Rotated].
+func (r *Rotator) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	out := input.Derive(imaging.Rotate(input.Image, r.angle, r.background))
+	out.Tags = out.Tags.With(Rotated)
+
+	return []Processed{out}, nil
+}
+
+// Flipper is a [Processor] that flips images horizontally or vertically.
+type Flipper struct {
+	vertical bool
+}
+
+// FlipH returns a [*Flipper] that flips images horizontally (left to right).
+func FlipH() *Flipper {
+	return &Flipper{}
+}
+
+// FlipV returns a [*Flipper] that flips images vertically (top to bottom).
+func FlipV() *Flipper {
+	return &Flipper{vertical: true}
+}
+
+// Process implements [Processor]. Flipped images keep the tags of their input
+// image and are additionally tagged with [Flipped].
+func (f *Flipper) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	var flipped *image.NRGBA
+	if f.vertical {
+		flipped = imaging.FlipV(input.Image)
+	} else {
+		flipped = imaging.FlipH(input.Image)
+	}
+
+	out := input.Derive(flipped)
+	out.Tags = out.Tags.With(Flipped)
+
+	return []Processed{out}, nil
+}
@@ -0,0 +1,158 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+// newGradient returns a 40x20 image whose pixels encode their coordinates in
+// the red and green channels.
+func newGradient() *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	return img
+}
+
+func runTransform(t *testing.T, proc image.Processor) image.Processed {
+	t.Helper()
+
+	pipe := image.Pipeline{image.Tag(image.Tags{"product"}), proc}
+
+	result, err := pipe.Run(context.Background(), newGradient())
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	img := result.Images[0]
+
+	if !img.Original {
+		t.Fatalf("transformed image should keep the %q flag", image.Original)
+	}
+
+	if !img.Tags.Contains("product") {
+		t.Fatalf("transformed image should keep the tags of its input; got %v", img.Tags)
+	}
+
+	if dim, _ := img.Meta.Dimensions(); dim != (image.Dimensions{img.Image.Bounds().Dx(), img.Image.Bounds().Dy()}) {
+		t.Fatalf("transformed image should have its dimensions in its metadata; got %v", dim)
+	}
+
+	return img
+}
+
+func pixelAt(img stdimage.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)).(color.NRGBA)
+}
+
+func TestCropper_Process(t *testing.T) {
+	tests := []struct {
+		name    string
+		cropper *image.Cropper
+		want    stdimage.Rectangle
+	}{
+		{"absolute", image.Crop(stdimage.Rect(5, 2, 25, 12)), stdimage.Rect(5, 2, 25, 12)},
+		{"clipped", image.Crop(stdimage.Rect(30, 10, 60, 60)), stdimage.Rect(30, 10, 40, 20)},
+		{"relative", image.CropRelative(0.25, 0.25, 0.5, 0.5), stdimage.Rect(10, 5, 30, 15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := runTransform(t, tt.cropper)
+
+			if size := img.Image.Bounds().Size(); size != tt.want.Size() {
+				t.Fatalf("cropped image should have size %v; got %v", tt.want.Size(), size)
+			}
+
+			if c := pixelAt(img.Image, 0, 0); int(c.R) != tt.want.Min.X || int(c.G) != tt.want.Min.Y {
+				t.Fatalf("cropped image should start at %v; got pixel %v", tt.want.Min, c)
+			}
+
+			if !img.Tags.Contains(image.Cropped) {
+				t.Fatalf("cropped image should have tag %q; got %v", image.Cropped, img.Tags)
+			}
+		})
+	}
+}
+
+func TestCropper_Process_empty(t *testing.T) {
+	pipe := image.Pipeline{image.Crop(stdimage.Rect(50, 50, 60, 60))}
+
+	if _, err := pipe.Run(context.Background(), newGradient()); !errors.Is(err, image.ErrEmptyCrop) {
+		t.Fatalf("Run() should fail with %q; got %v", image.ErrEmptyCrop, err)
+	}
+}
+
+func TestRotator_Process(t *testing.T) {
+	tests := []struct {
+		angle    float64
+		wantSize stdimage.Point
+		// wantTopLeft is the pixel of the input image that is at the top-left
+		// corner of the rotated image.
+		wantTopLeft stdimage.Point
+	}{
+		{90, stdimage.Pt(20, 40), stdimage.Pt(39, 0)},
+		{180, stdimage.Pt(40, 20), stdimage.Pt(39, 19)},
+		{270, stdimage.Pt(20, 40), stdimage.Pt(0, 19)},
+		{-90, stdimage.Pt(20, 40), stdimage.Pt(0, 19)},
+	}
+
+	for _, tt := range tests {
+		img := runTransform(t, image.Rotate(tt.angle))
+
+		if size := img.Image.Bounds().Size(); size != tt.wantSize {
+			t.Fatalf("image rotated by %v° should have size %v; got %v", tt.angle, tt.wantSize, size)
+		}
+
+		if c := pixelAt(img.Image, 0, 0); int(c.R) != tt.wantTopLeft.X || int(c.G) != tt.wantTopLeft.Y {
+			t.Fatalf("top-left pixel of image rotated by %v° should be input pixel %v; got %v", tt.angle, tt.wantTopLeft, c)
+		}
+
+		if !img.Tags.Contains(image.Rotated) {
+			t.Fatalf("rotated image should have tag %q; got %v", image.Rotated, img.Tags)
+		}
+	}
+}
+
+func TestRotator_Process_background(t *testing.T) {
+	bg := color.NRGBA{0, 0, 255, 255}
+	img := runTransform(t, image.Rotate(45, image.RotateBackground(bg)))
+
+	if size := img.Image.Bounds().Size(); size.X <= 40 || size.Y <= 20 {
+		t.Fatalf("image rotated by 45° should be enlarged; got size %v", size)
+	}
+
+	if c := pixelAt(img.Image, 0, 0); c != bg {
+		t.Fatalf("uncovered areas should have the background color %v; got %v", bg, c)
+	}
+}
+
+func TestFlipper_Process(t *testing.T) {
+	h := runTransform(t, image.FlipH())
+	if c := pixelAt(h.Image, 0, 0); c.R != 39 || c.G != 0 {
+		t.Fatalf("top-left pixel of horizontally flipped image should be input pixel (39,0); got %v", c)
+	}
+
+	v := runTransform(t, image.FlipV())
+	if c := pixelAt(v.Image, 0, 0); c.R != 0 || c.G != 19 {
+		t.Fatalf("top-left pixel of vertically flipped image should be input pixel (0,19); got %v", c)
+	}
+
+	for _, img := range []image.Processed{h, v} {
+		if !img.Tags.Contains(image.Flipped) {
+			t.Fatalf("flipped image should have tag %q; got %v", image.Flipped, img.Tags)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+)
+
+var _ Processor = (*Trimmer)(nil)
+
+const (
+	// Trimmed is the tag that is assigned to trimmed images.
+	Trimmed = "trimmed"
+
+	// MetaTrim is the rectangle of the input image that was kept by the
+	// [Trimmer], in the coordinates of the input image ([image.Rectangle]).
+	MetaTrim = "trim"
+)
+
+// Trimmer is a [Processor] that trims uniform borders from images.
+type Trimmer struct {
+	color     color.Color
+	tolerance uint8
+	margin    int
+}
+
+// TrimOption is an option for a [*Trimmer].
+type TrimOption func(*Trimmer)
+
+// TrimColor returns a TrimOption that sets the color of the borders to trim.
+// By default, the color of the corners of an image is used, and images are
+// only trimmed if all 4 corners have the same color, within the tolerance.
+func TrimColor(c color.Color) TrimOption {
+	return func(t *Trimmer) {
+		t.color = c
+	}
+}
+
+// TrimTolerance returns a TrimOption that sets the maximum difference of each
+// color channel (0-255) of a pixel to the border color for the pixel to be
+// part of the border. Defaults to 0.
+func TrimTolerance(tolerance uint8) TrimOption {
+	return func(t *Trimmer) {
+		t.tolerance = tolerance
+	}
+}
+
+// TrimMargin returns a TrimOption that pads trimmed images with a margin of
+// the given number of pixels on each side, filled with the border color.
+// Negative values are treated as 0.
+func TrimMargin(px int) TrimOption {
+	return func(t *Trimmer) {
+		t.margin = max(px, 0)
+	}
+}
+
+// Trim returns a [*Trimmer] that trims uniform borders from images.
+// Trimmed images keep the tags of their input image, are additionally tagged
+// with [Trimmed] and have the kept rectangle of the input image in their
+// [Metadata] under [MetaTrim]. Images without borders, or that consist only of
+// the border color, are passed through unchanged.
+func Trim(opts ...TrimOption) *Trimmer {
+	var t Trimmer
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return &t
+}
+
+// Process implements [Processor].
+func (t *Trimmer) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	img := imaging.Clone(input.Image)
+	rect, border := t.detect(img)
+
+	if rect.Empty() || rect == img.Bounds() {
+		return []Processed{input}, nil
+	}
+
+	trimmed := image.NewNRGBA(image.Rect(0, 0, rect.Dx()+2*t.margin, rect.Dy()+2*t.margin))
+	if t.margin > 0 {
+		draw.Draw(trimmed, trimmed.Bounds(), image.NewUniform(border), image.Point{}, draw.Src)
+	}
+	draw.Draw(trimmed, trimmed.Bounds().Inset(t.margin), img, rect.Min, draw.Src)
+
+	out := input.Derive(trimmed)
+	out.Tags = out.Tags.With(Trimmed)
+	out.Meta[MetaTrim] = rect.Add(input.Image.Bounds().Min)
+
+	return []Processed{out}, nil
+}
+
+// detect returns the rectangle of img that remains after trimming the borders,
+// and the border color.
+func (t *Trimmer) detect(img *image.NRGBA) (image.Rectangle, color.NRGBA) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return bounds, color.NRGBA{}
+	}
+
+	border := img.NRGBAAt(bounds.Min.X, bounds.Min.Y)
+	if t.color != nil {
+		border = color.NRGBAModel.Convert(t.color).(color.NRGBA)
+	}
+
+	isBorder := func(x, y int) bool {
+		c := img.NRGBAAt(x, y)
+		return channelDiff(c.R, border.R) <= t.tolerance &&
+			channelDiff(c.G, border.G) <= t.tolerance &&
+			channelDiff(c.B, border.B) <= t.tolerance &&
+			channelDiff(c.A, border.A) <= t.tolerance
+	}
+
+	// Without an explicit color, a single corner could be part of the
+	// content, so all corners must agree on the border color.
+	if t.color == nil {
+		for _, corner := range []image.Point{
+			{bounds.Max.X - 1, bounds.Min.Y},
+			{bounds.Min.X, bounds.Max.Y - 1},
+			{bounds.Max.X - 1, bounds.Max.Y - 1},
+		} {
+			if !isBorder(corner.X, corner.Y) {
+				return bounds.Sub(bounds.Min), border
+			}
+		}
+	}
+
+	rowIsBorder := func(y, minX, maxX int) bool {
+		for x := minX; x < maxX; x++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	colIsBorder := func(x, minY, maxY int) bool {
+		for y := minY; y < maxY; y++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	rect := bounds
+	for rect.Min.Y < rect.Max.Y && rowIsBorder(rect.Min.Y, rect.Min.X, rect.Max.X) {
+		rect.Min.Y++
+	}
+	for rect.Max.Y > rect.Min.Y && rowIsBorder(rect.Max.Y-1, rect.Min.X, rect.Max.X) {
+		rect.Max.Y--
+	}
+	for rect.Min.X < rect.Max.X && colIsBorder(rect.Min.X, rect.Min.Y, rect.Max.Y) {
+		rect.Min.X++
+	}
+	for rect.Max.X > rect.Min.X && colIsBorder(rect.Max.X-1, rect.Min.Y, rect.Max.Y) {
+		rect.Max.X--
+	}
+
+	return rect.Sub(bounds.Min), border
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,164 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+// newBordered returns a 100x80 white image with a red rectangle at rect whose
+// top-left pixel is slightly off-white.
+func newBordered(rect stdimage.Rectangle) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 100, 80))
+	draw.Draw(img, img.Bounds(), stdimage.NewUniform(color.NRGBA{255, 255, 255, 255}), stdimage.Point{}, draw.Src)
+	draw.Draw(img, rect, stdimage.NewUniform(color.NRGBA{255, 0, 0, 255}), stdimage.Point{}, draw.Src)
+	img.SetNRGBA(99, 79, color.NRGBA{250, 252, 251, 255})
+	return img
+}
+
+func TestTrimmer_Process(t *testing.T) {
+	rect := stdimage.Rect(10, 5, 70, 60)
+
+	tests := []struct {
+		name     string
+		opts     []image.TrimOption
+		wantSize stdimage.Point
+		wantRect stdimage.Rectangle
+	}{
+		{
+			name:     "tolerance",
+			opts:     []image.TrimOption{image.TrimTolerance(8)},
+			wantSize: rect.Size(),
+			wantRect: rect,
+		},
+		{
+			name:     "color",
+			opts:     []image.TrimOption{image.TrimColor(color.White), image.TrimTolerance(8)},
+			wantSize: rect.Size(),
+			wantRect: rect,
+		},
+		{
+			name:     "margin",
+			opts:     []image.TrimOption{image.TrimTolerance(8), image.TrimMargin(4)},
+			wantSize: rect.Size().Add(stdimage.Pt(8, 8)),
+			wantRect: rect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipe := image.Pipeline{image.Tag(image.Tags{"product"}), image.Trim(tt.opts...)}
+
+			result, err := pipe.Run(context.Background(), newBordered(rect))
+			if err != nil {
+				t.Fatalf("run pipeline: %v", err)
+			}
+
+			if len(result.Images) != 1 {
+				t.Fatalf("expected 1 image; got %d", len(result.Images))
+			}
+
+			trimmed := result.Images[0]
+
+			if size := trimmed.Image.Bounds().Size(); size != tt.wantSize {
+				t.Fatalf("trimmed image should have size %v; got %v", tt.wantSize, size)
+			}
+
+			if !trimmed.Tags.Contains(image.Trimmed) || !trimmed.Tags.Contains("product") {
+				t.Fatalf("trimmed image should have tags %q and %q; got %v", image.Trimmed, "product", trimmed.Tags)
+			}
+
+			if got, ok := image.MetaValue[stdimage.Rectangle](trimmed.Meta, image.MetaTrim); !ok || got != tt.wantRect {
+				t.Fatalf("trimmed image should have %q metadata %v; got %v", image.MetaTrim, tt.wantRect, trimmed.Meta[image.MetaTrim])
+			}
+
+			if !trimmed.Original {
+				t.Fatalf("trimmed image should keep the %q flag", image.Original)
+			}
+		})
+	}
+}
+
+func TestTrimmer_Process_corners(t *testing.T) {
+	// The bottom-right corner of the bordered image is off-white, so the
+	// corners only agree with a tolerance.
+	content := stdimage.Rect(10, 5, 70, 60)
+
+	tests := map[string]struct {
+		rect stdimage.Rectangle
+		opts []image.TrimOption
+		want stdimage.Point
+	}{
+		"no tolerance":        {rect: content, want: stdimage.Pt(100, 80)},
+		"tolerance":           {rect: content, opts: []image.TrimOption{image.TrimTolerance(8)}, want: content.Size()},
+		"color, no tolerance": {rect: content, opts: []image.TrimOption{image.TrimColor(color.White)}, want: stdimage.Pt(90, 75)},
+		"content in a corner": {rect: stdimage.Rect(0, 0, 70, 60), opts: []image.TrimOption{image.TrimTolerance(8)}, want: stdimage.Pt(100, 80)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := image.Pipeline{image.Trim(tt.opts...)}.Run(context.Background(), newBordered(tt.rect))
+			if err != nil {
+				t.Fatalf("run pipeline: %v", err)
+			}
+
+			if size := result.Images[0].Image.Bounds().Size(); size != tt.want {
+				t.Fatalf("image should have size %v; got %v", tt.want, size)
+			}
+		})
+	}
+}
+
+func TestTrimmer_Process_margin(t *testing.T) {
+	pipe := image.Pipeline{image.Trim(image.TrimColor(color.White), image.TrimTolerance(8), image.TrimMargin(4))}
+
+	result, err := pipe.Run(context.Background(), newBordered(stdimage.Rect(10, 5, 70, 60)))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	img := result.Images[0].Image.(*stdimage.NRGBA)
+
+	if c := img.NRGBAAt(0, 0); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("margin should have the border color; got %v", c)
+	}
+
+	if c := img.NRGBAAt(4, 4); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("trimmed content should start after the margin; got %v", c)
+	}
+}
+
+func TestTrimmer_Process_negativeMargin(t *testing.T) {
+	rect := stdimage.Rect(10, 5, 70, 60)
+	pipe := image.Pipeline{image.Trim(image.TrimTolerance(8), image.TrimMargin(-4))}
+
+	result, err := pipe.Run(context.Background(), newBordered(rect))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if size := result.Images[0].Image.Bounds().Size(); size != rect.Size() {
+		t.Fatalf("negative margin should be treated as 0; got size %v, want %v", size, rect.Size())
+	}
+}
+
+func TestTrimmer_Process_noBorder(t *testing.T) {
+	for _, img := range []stdimage.Image{newCheckerboard(10), stdimage.NewNRGBA(stdimage.Rect(0, 0, 10, 10))} {
+		result, err := image.Pipeline{image.Trim()}.Run(context.Background(), img)
+		if err != nil {
+			t.Fatalf("run pipeline: %v", err)
+		}
+
+		if result.Images[0].Image != img {
+			t.Fatalf("image without borders should be passed through")
+		}
+
+		if result.Images[0].Tags.Contains(image.Trimmed) {
+			t.Fatalf("image without borders should not have tag %q", image.Trimmed)
+		}
+	}
+}
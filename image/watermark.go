@@ -0,0 +1,249 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+var _ Processor = (*Watermarker)(nil)
+
+// Watermarked is the tag that is assigned to watermarked images.
+const Watermarked = "watermarked"
+
+// Gravity is the position of an overlay within an image.
+type Gravity int
+
+const (
+	// GravityCenter places the overlay at the center of the image.
+	GravityCenter Gravity = iota
+	// GravityNorth places the overlay at the top center of the image.
+	GravityNorth
+	// GravityNorthEast places the overlay at the top-right corner of the image.
+	GravityNorthEast
+	// GravityEast places the overlay at the right center of the image.
+	GravityEast
+	// GravitySouthEast places the overlay at the bottom-right corner of the image.
+	GravitySouthEast
+	// GravitySouth places the overlay at the bottom center of the image.
+	GravitySouth
+	// GravitySouthWest places the overlay at the bottom-left corner of the image.
+	GravitySouthWest
+	// GravityWest places the overlay at the left center of the image.
+	GravityWest
+	// GravityNorthWest places the overlay at the top-left corner of the image.
+	GravityNorthWest
+)
+
+func (g Gravity) String() string {
+	switch g {
+	case GravityCenter:
+		return "center"
+	case GravityNorth:
+		return "north"
+	case GravityNorthEast:
+		return "northeast"
+	case GravityEast:
+		return "east"
+	case GravitySouthEast:
+		return "southeast"
+	case GravitySouth:
+		return "south"
+	case GravitySouthWest:
+		return "southwest"
+	case GravityWest:
+		return "west"
+	case GravityNorthWest:
+		return "northwest"
+	default:
+		return "unknown"
+	}
+}
+
+// position returns the top-left position of an overlay of the given size
+// within bounds. The offset moves the overlay away from the edges that the
+// gravity points to; for centered axes, it moves the overlay right or down.
+func (g Gravity) position(bounds image.Rectangle, size image.Point, offset image.Point) image.Point {
+	x := bounds.Min.X + (bounds.Dx()-size.X)/2 + offset.X
+	y := bounds.Min.Y + (bounds.Dy()-size.Y)/2 + offset.Y
+
+	switch g {
+	case GravityNorthWest, GravityWest, GravitySouthWest:
+		x = bounds.Min.X + offset.X
+	case GravityNorthEast, GravityEast, GravitySouthEast:
+		x = bounds.Max.X - size.X - offset.X
+	}
+
+	switch g {
+	case GravityNorthWest, GravityNorth, GravityNorthEast:
+		y = bounds.Min.Y + offset.Y
+	case GravitySouthWest, GravitySouth, GravitySouthEast:
+		y = bounds.Max.Y - size.Y - offset.Y
+	}
+
+	return image.Pt(x, y)
+}
+
+// Watermarker is a [Processor] that composites an overlay image, like a logo,
+// onto images.
+type Watermarker struct {
+	overlay image.Image
+	gravity Gravity
+	offset  image.Point
+	scale   float64
+	opacity float64
+	tile    bool
+	spacing int
+
+	// mux guards scaled, the overlay scaled for the image width of the last
+	// Process call. Images of a Pipeline run commonly share their widths, so
+	// a single entry avoids most rescaling without holding on to overlays
+	// for every width that was ever processed.
+	mux    sync.Mutex
+	scaled struct {
+		width   int
+		overlay image.Image
+	}
+}
+
+// WatermarkOption is an option for a [*Watermarker].
+type WatermarkOption func(*Watermarker)
+
+// WatermarkGravity returns a WatermarkOption that sets the position of the
+// overlay. Defaults to [GravitySouthEast].
+func WatermarkGravity(g Gravity) WatermarkOption {
+	return func(w *Watermarker) {
+		w.gravity = g
+	}
+}
+
+// WatermarkOffset returns a WatermarkOption that moves the overlay away from
+// the edges of the image that the [Gravity] points to, in pixels.
+func WatermarkOffset(x, y int) WatermarkOption {
+	return func(w *Watermarker) {
+		w.offset = image.Pt(x, y)
+	}
+}
+
+// WatermarkScale returns a WatermarkOption that scales the overlay to the
+// given fraction of the width of the image, preserving the aspect ratio of
+// the overlay, e.g. 0.2 for 20% of the image width. By default, the overlay
+// is not scaled.
+func WatermarkScale(scale float64) WatermarkOption {
+	return func(w *Watermarker) {
+		w.scale = scale
+	}
+}
+
+// WatermarkOpacity returns a WatermarkOption that sets the opacity of the
+// overlay, between 0 and 1. Defaults to 1.
+func WatermarkOpacity(opacity float64) WatermarkOption {
+	return func(w *Watermarker) {
+		w.opacity = math.Max(0, math.Min(1, opacity))
+	}
+}
+
+// WatermarkTile returns a WatermarkOption that repeats the overlay across the
+// whole image, with the given spacing between the tiles, in pixels. The
+// [Gravity] and offset determine the position of one of the tiles.
+func WatermarkTile(spacing int) WatermarkOption {
+	return func(w *Watermarker) {
+		w.tile = true
+		w.spacing = spacing
+	}
+}
+
+// Watermark returns a [*Watermarker] that composites the given overlay onto
+// images. Watermarked images keep the tags of their input image and are
+// additionally tagged with [Watermarked].
+//
+// To watermark only some of the images, wrap the [*Watermarker] in a
+// [*Conditional]. For example, to skip the smallest images:
+//
+//	q := image.MustParseQuery("NOT size=xs")
+//	image.When(func(img image.Processed) bool {
+//		return q.Match(img.Tags)
+//	}, image.Watermark(logo))
+func Watermark(overlay image.Image, opts ...WatermarkOption) *Watermarker {
+	w := &Watermarker{
+		overlay: overlay,
+		gravity: GravitySouthEast,
+		opacity: 1,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Process implements [Processor].
+func (w *Watermarker) Process(ctx ProcessorContext) ([]Processed, error) {
+	input := ctx.Image()
+
+	out := input.Derive(w.apply(input.Image))
+	out.Tags = out.Tags.With(Watermarked)
+
+	return []Processed{out}, nil
+}
+
+// scaledOverlay returns the overlay scaled for an image of the given width.
+func (w *Watermarker) scaledOverlay(width int) image.Image {
+	if w.scale <= 0 {
+		return w.overlay
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.scaled.overlay == nil || w.scaled.width != width {
+		overlayWidth := max(1, int(math.Round(float64(width)*w.scale)))
+		w.scaled.width = width
+		w.scaled.overlay = imaging.Resize(w.overlay, overlayWidth, 0, imaging.Lanczos)
+	}
+
+	return w.scaled.overlay
+}
+
+func (w *Watermarker) apply(img image.Image) *image.NRGBA {
+	dst := imaging.Clone(img)
+	bounds := dst.Bounds()
+
+	overlay := w.scaledOverlay(bounds.Dx())
+
+	size := overlay.Bounds().Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return dst
+	}
+
+	mask := image.NewUniform(color.Alpha{A: uint8(w.opacity*255 + 0.5)})
+	pos := w.gravity.position(bounds, size, w.offset)
+
+	if !w.tile {
+		draw.DrawMask(dst, image.Rectangle{Min: pos, Max: pos.Add(size)}, overlay, overlay.Bounds().Min, mask, image.Point{}, draw.Over)
+		return dst
+	}
+
+	stepX, stepY := size.X+max(0, w.spacing), size.Y+max(0, w.spacing)
+
+	// Move the start position to the top-left, so that the tiles cover the
+	// whole image and one of the tiles is at pos.
+	start := image.Pt(
+		pos.X-int(math.Ceil(float64(pos.X-bounds.Min.X)/float64(stepX)))*stepX,
+		pos.Y-int(math.Ceil(float64(pos.Y-bounds.Min.Y)/float64(stepY)))*stepY,
+	)
+
+	for y := start.Y; y < bounds.Max.Y; y += stepY {
+		for x := start.X; x < bounds.Max.X; x += stepX {
+			r := image.Rect(x, y, x+size.X, y+size.Y)
+			draw.DrawMask(dst, r, overlay, overlay.Bounds().Min, mask, image.Point{}, draw.Over)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,175 @@
+package image_test
+
+import (
+	"context"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/modernice/media-tools/image"
+)
+
+func newUniform(width, height int, c color.Color) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), stdimage.NewUniform(c), stdimage.Point{}, draw.Src)
+	return img
+}
+
+func watermark(t *testing.T, opts ...image.WatermarkOption) image.Processed {
+	t.Helper()
+
+	logo := newUniform(10, 10, color.Black)
+	pipe := image.Pipeline{image.Watermark(logo, opts...)}
+
+	result, err := pipe.Run(context.Background(), newUniform(100, 50, color.White))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	if len(result.Images) != 1 {
+		t.Fatalf("expected 1 image; got %d", len(result.Images))
+	}
+
+	img := result.Images[0]
+
+	if !img.Tags.Contains(image.Watermarked) {
+		t.Fatalf("watermarked image should have tag %q; got %v", image.Watermarked, img.Tags)
+	}
+
+	if !img.Original {
+		t.Fatalf("watermarked image should keep the %q flag", image.Original)
+	}
+
+	return img
+}
+
+func expectPixel(t *testing.T, img stdimage.Image, x, y int, want uint8) {
+	t.Helper()
+
+	if c := pixelAt(img, x, y); c.R != want {
+		t.Fatalf("pixel (%d,%d) should have value %d; got %v", x, y, want, c)
+	}
+}
+
+func TestWatermarker_Process(t *testing.T) {
+	img := watermark(t, image.WatermarkOffset(5, 5)).Image
+
+	expectPixel(t, img, 85, 35, 0)
+	expectPixel(t, img, 94, 44, 0)
+	expectPixel(t, img, 84, 35, 255)
+	expectPixel(t, img, 95, 45, 255)
+}
+
+func TestWatermarker_Process_gravity(t *testing.T) {
+	tests := []struct {
+		gravity image.Gravity
+		topLeft stdimage.Point
+	}{
+		{image.GravityNorthWest, stdimage.Pt(0, 0)},
+		{image.GravityNorth, stdimage.Pt(45, 0)},
+		{image.GravityCenter, stdimage.Pt(45, 20)},
+		{image.GravityWest, stdimage.Pt(0, 20)},
+		{image.GravitySouthWest, stdimage.Pt(0, 40)},
+		{image.GravityEast, stdimage.Pt(90, 20)},
+	}
+
+	for _, tt := range tests {
+		img := watermark(t, image.WatermarkGravity(tt.gravity)).Image
+
+		expectPixel(t, img, tt.topLeft.X, tt.topLeft.Y, 0)
+		expectPixel(t, img, tt.topLeft.X+9, tt.topLeft.Y+9, 0)
+
+		if tt.topLeft.X > 0 {
+			expectPixel(t, img, tt.topLeft.X-1, tt.topLeft.Y, 255)
+		}
+	}
+}
+
+func TestWatermarker_Process_opacity(t *testing.T) {
+	img := watermark(t, image.WatermarkOpacity(0.5)).Image
+
+	if c := pixelAt(img, 95, 45); c.R < 120 || c.R > 135 {
+		t.Fatalf("half-transparent overlay should produce a gray of ~128; got %v", c)
+	}
+}
+
+func TestWatermarker_Process_scale(t *testing.T) {
+	img := watermark(t, image.WatermarkScale(0.2)).Image
+
+	expectPixel(t, img, 80, 30, 0)
+	expectPixel(t, img, 79, 30, 255)
+	expectPixel(t, img, 80, 29, 255)
+}
+
+func TestWatermarker_Process_scale_sizes(t *testing.T) {
+	logo := newUniform(10, 10, color.Black)
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"sm": {50}, "lg": {100}}, image.DiscardInput(true)),
+		image.Watermark(logo, image.WatermarkScale(0.2)),
+	}
+
+	result, err := pipe.Run(context.Background(), newUniform(100, 50, color.White))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	for size, overlay := range map[string]int{"sm": 10, "lg": 20} {
+		img, err := result.Single("size=" + size)
+		if err != nil {
+			t.Fatalf("find %q image: %v", "size="+size, err)
+		}
+
+		b := img.Image.Bounds()
+		expectPixel(t, img.Image, b.Max.X-overlay, b.Max.Y-1, 0)
+		expectPixel(t, img.Image, b.Max.X-overlay-1, b.Max.Y-1, 255)
+	}
+}
+
+func TestWatermarker_Process_tile(t *testing.T) {
+	img := watermark(t, image.WatermarkGravity(image.GravityNorthWest), image.WatermarkTile(10)).Image
+
+	for _, x := range []int{0, 20, 40, 60, 80} {
+		expectPixel(t, img, x, 0, 0)
+		expectPixel(t, img, x, 40, 0)
+		expectPixel(t, img, x+15, 0, 255)
+	}
+
+	expectPixel(t, img, 0, 15, 255)
+}
+
+func TestWatermarker_Process_when(t *testing.T) {
+	logo := newUniform(10, 10, color.Black)
+	q := image.MustParseQuery("NOT size=xs")
+
+	pipe := image.Pipeline{
+		image.Resize(image.DimensionMap{"xs": {20}, "lg": {60}}, image.DiscardInput(true)),
+		image.When(func(img image.Processed) bool {
+			return q.Match(img.Tags)
+		}, image.Watermark(logo)),
+	}
+
+	result, err := pipe.Run(context.Background(), newUniform(100, 50, color.White))
+	if err != nil {
+		t.Fatalf("run pipeline: %v", err)
+	}
+
+	xs, err := result.Single("size=xs")
+	if err != nil {
+		t.Fatalf("find %q image: %v", "size=xs", err)
+	}
+
+	if xs.Tags.Contains(image.Watermarked) {
+		t.Fatalf("%q image should not be watermarked", "size=xs")
+	}
+
+	lg, err := result.Single("size=lg")
+	if err != nil {
+		t.Fatalf("find %q image: %v", "size=lg", err)
+	}
+
+	if !lg.Tags.Contains(image.Watermarked) {
+		t.Fatalf("%q image should be watermarked; got tags %v", "size=lg", lg.Tags)
+	}
+}